@@ -28,9 +28,10 @@ type Common interface {
 	// MethodGroup: Auth
 
 	// AuthVerify checks whether the specified token is valid and returns the list of permissions associated with it.
-	AuthVerify(ctx context.Context, token string) ([]auth.Permission, error) //perm:read
-	// AuthNew creates a new token with the specified list of permissions.
-	AuthNew(ctx context.Context, perms []auth.Permission) ([]byte, error) //perm:admin
+	AuthVerify(ctx context.Context, token string) ([]auth.Permission, error) //perm:read scope:any
+	// AuthNew creates a new token with the specified list of permissions, a token with a scope
+	// can only operate the deployments of the scope owner.
+	AuthNew(ctx context.Context, perms []auth.Permission, scope *types.AuthScope) ([]byte, error) //perm:admin
 
 	// MethodGroup: Log
 
@@ -44,16 +45,16 @@ type Common interface {
 	// MethodGroup: Common
 
 	// Version provides information about API provider
-	Version(context.Context) (APIVersion, error) //perm:read
+	Version(context.Context) (APIVersion, error) //perm:read scope:any
 	// Discover returns an OpenRPC document describing an RPC API.
 	Discover(ctx context.Context) (types.OpenRPCDocument, error) //perm:admin
 	// Shutdown trigger graceful shutdown
 	Shutdown(context.Context) error //perm:admin
 	// Session returns a UUID of api provider session
-	Session(ctx context.Context) (uuid.UUID, error) //perm:admin
+	Session(ctx context.Context) (uuid.UUID, error) //perm:admin scope:any
 
 	// Closing jsonrpc closing
-	Closing(context.Context) (<-chan struct{}, error) //perm:admin
+	Closing(context.Context) (<-chan struct{}, error) //perm:admin scope:any
 }
 
 // APIVersion provides various build-time information
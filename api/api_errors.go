@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -9,6 +11,8 @@ import (
 
 const (
 	EUnknown = iota + jsonrpc.FirstUserCode
+	EQuotaExceeded
+	EInsufficientCapacity
 )
 
 type ErrUnknown struct{}
@@ -17,6 +21,60 @@ func (e *ErrUnknown) Error() string {
 	return "unknown"
 }
 
+// ErrQuotaExceeded is returned when a deployment change would take the owner over its quota.
+type ErrQuotaExceeded struct {
+	Owner string
+	// Resource is one of deployments, cpu, memory or storage
+	Resource  string
+	Limit     float64
+	Requested float64
+}
+
+func (e *ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("owner %s exceeds the %s quota: %v requested, %v allowed", e.Owner, e.Resource, e.Requested, e.Limit)
+}
+
+type errQuotaExceeded ErrQuotaExceeded
+
+// MarshalJSON carries the details of the error across the rpc.
+func (e *ErrQuotaExceeded) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*errQuotaExceeded)(e))
+}
+
+func (e *ErrQuotaExceeded) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*errQuotaExceeded)(e))
+}
+
+// ErrInsufficientCapacity is returned by a provider when a pod of the deployment cannot be scheduled on any of its nodes.
+type ErrInsufficientCapacity struct {
+	Deployment string
+	Service    string
+	// Resource is one of cpu, memory or storage, empty if every resource fits on some node but not on the same one
+	Resource string
+	// Requested is the request of a single pod and Available the most free on a node, the cpu in cores and the memory and storage in MB
+	Requested float64
+	Available float64
+}
+
+func (e *ErrInsufficientCapacity) Error() string {
+	if e.Resource == "" {
+		return fmt.Sprintf("service %s of deployment %s does not fit on any node of the provider", e.Service, e.Deployment)
+	}
+	return fmt.Sprintf("service %s of deployment %s does not fit on any node of the provider: %v %s requested, at most %v free on a node",
+		e.Service, e.Deployment, e.Requested, e.Resource, e.Available)
+}
+
+type errInsufficientCapacity ErrInsufficientCapacity
+
+// MarshalJSON carries the details of the error across the rpc.
+func (e *ErrInsufficientCapacity) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*errInsufficientCapacity)(e))
+}
+
+func (e *ErrInsufficientCapacity) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*errInsufficientCapacity)(e))
+}
+
 var RPCErrors = jsonrpc.NewErrors()
 
 func ErrorIsIn(err error, errorTypes []error) bool {
@@ -31,4 +89,6 @@ func ErrorIsIn(err error, errorTypes []error) bool {
 
 func init() {
 	RPCErrors.Register(EUnknown, new(*ErrUnknown))
+	RPCErrors.Register(EQuotaExceeded, new(*ErrQuotaExceeded))
+	RPCErrors.Register(EInsufficientCapacity, new(*ErrInsufficientCapacity))
 }
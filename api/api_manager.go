@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/gnasnik/titan-container/api/types"
 )
@@ -10,14 +11,29 @@ import (
 type Manager interface {
 	Common
 
-	GetStatistics(ctx context.Context, id types.ProviderID) (*types.ResourcesStatistics, error)         //perm:read
-	ProviderConnect(ctx context.Context, url string, provider *types.Provider) error                    //perm:admin
-	GetProviderList(ctx context.Context, option *types.GetProviderOption) ([]*types.Provider, error)    //perm:read
-	GetDeploymentList(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.Deployment, error) //perm:read
-	CreateDeployment(ctx context.Context, deployment *types.Deployment) error                           //perm:admin
-	UpdateDeployment(ctx context.Context, deployment *types.Deployment) error                           //perm:admin
-	CloseDeployment(ctx context.Context, deployment *types.Deployment) error                            //perm:admin
-	GetLogs(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceLog, error)             //perm:read
-	GetEvents(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceEvent, error)         //perm:read
-	SetProperties(ctx context.Context, properties *types.Properties) error                              //perm:admin
+	GetStatistics(ctx context.Context, id types.ProviderID) (*types.ResourcesStatistics, error)                                //perm:read
+	ProviderConnect(ctx context.Context, url string, provider *types.Provider) error                                           //perm:admin
+	GetProviderList(ctx context.Context, option *types.GetProviderOption) ([]*types.Provider, error)                           //perm:read
+	GetProviderStateChanges(ctx context.Context, option *types.GetProviderOption) ([]*types.ProviderStateChange, error)        //perm:read
+	GetDeploymentList(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.Deployment, error)                        //perm:read scope:deployment
+	CreateDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error)                              //perm:write scope:deployment
+	UpdateDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error)                              //perm:write scope:deployment
+	CloseDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error)                               //perm:write scope:deployment
+	ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error                        //perm:admin scope:deployment
+	TopUpDeployment(ctx context.Context, id types.DeploymentID, amount float64, duration time.Duration) error                  //perm:admin
+	GetDeploymentRevisions(ctx context.Context, id types.DeploymentID) ([]*types.DeploymentRevision, error)                    //perm:read scope:deployment
+	GetDeploymentStateChanges(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error)     //perm:read scope:deployment
+	RollbackDeployment(ctx context.Context, id types.DeploymentID, revision int) (*types.Operation, error)                     //perm:admin scope:deployment
+	GetOperation(ctx context.Context, id types.OperationID) (*types.Operation, error)                                          //perm:read scope:deployment
+	WatchOperation(ctx context.Context, id types.OperationID) (<-chan *types.Operation, error)                                 //perm:read scope:deployment
+	GetLogs(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceLog, error)                                    //perm:read scope:deployment
+	GetLogStream(ctx context.Context, deployment *types.Deployment, opt *types.LogOption) (<-chan *types.LogLine, error)       //perm:read scope:deployment
+	GetEvents(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceEvent, error)                                //perm:read scope:deployment
+	GetDeploymentMetrics(ctx context.Context, deployment *types.Deployment) (*types.DeploymentMetrics, error)                  //perm:read scope:deployment
+	ExecDeployment(ctx context.Context, deployment *types.Deployment, opt *types.ExecOption) (<-chan *types.ExecOutput, error) //perm:admin
+	ExecInput(ctx context.Context, deployment *types.Deployment, input *types.ExecInput) error                                 //perm:admin
+	SetQuota(ctx context.Context, quota *types.Quota) error                                                                    //perm:admin
+	GetQuota(ctx context.Context, owner string) (*types.QuotaUsage, error)                                                     //perm:read scope:owner
+	GetUsage(ctx context.Context, owner string, from, to time.Time) ([]*types.Usage, error)                                    //perm:read scope:owner
+	SetProperties(ctx context.Context, properties *types.Properties) error                                                     //perm:admin
 }
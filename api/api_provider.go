@@ -8,13 +8,22 @@ import (
 )
 
 type Provider interface {
-	GetStatistics(ctx context.Context) (*types.ResourcesStatistics, error)               //perm:read
-	GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) //perm:read
-	CreateDeployment(ctx context.Context, deployment *types.Deployment) error            //perm:admin
-	UpdateDeployment(ctx context.Context, deployment *types.Deployment) error            //perm:admin
-	CloseDeployment(ctx context.Context, deployment *types.Deployment) error             //perm:admin
-	GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.ServiceLog, error)     //perm:read
-	GetEvents(ctx context.Context, id types.DeploymentID) ([]*types.ServiceEvent, error) //perm:read
+	GetStatistics(ctx context.Context) (*types.ResourcesStatistics, error)                                              //perm:read
+	CheckCapacity(ctx context.Context, deployment *types.Deployment) error                                              //perm:admin
+	GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error)                                //perm:read
+	GetAppliedDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error)                         //perm:read
+	ListDeployments(ctx context.Context) ([]*types.Deployment, error)                                                   //perm:read
+	WatchDeployments(ctx context.Context) (<-chan *types.Deployment, error)                                             //perm:read
+	CreateDeployment(ctx context.Context, deployment *types.Deployment) error                                           //perm:admin
+	UpdateDeployment(ctx context.Context, deployment *types.Deployment) error                                           //perm:admin
+	CloseDeployment(ctx context.Context, deployment *types.Deployment) error                                            //perm:admin
+	ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error                 //perm:admin
+	GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.ServiceLog, error)                                    //perm:read
+	GetLogStream(ctx context.Context, id types.DeploymentID, opt *types.LogOption) (<-chan *types.LogLine, error)       //perm:read
+	GetEvents(ctx context.Context, id types.DeploymentID) ([]*types.ServiceEvent, error)                                //perm:read
+	GetDeploymentMetrics(ctx context.Context, id types.DeploymentID) (*types.DeploymentMetrics, error)                  //perm:read
+	ExecDeployment(ctx context.Context, id types.DeploymentID, opt *types.ExecOption) (<-chan *types.ExecOutput, error) //perm:admin
+	ExecInput(ctx context.Context, input *types.ExecInput) error                                                        //perm:admin
 
 	Version(context.Context) (Version, error)   //perm:admin
 	Session(context.Context) (uuid.UUID, error) //perm:admin
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"context"
+	"reflect"
+
 	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gnasnik/titan-container/api/types"
+	"golang.org/x/xerrors"
 )
 
 const (
@@ -16,11 +21,131 @@ const (
 var AllPermissions = []auth.Permission{PermRead, PermWrite, PermSign, PermAdmin}
 var DefaultPerms = []auth.Permission{PermRead}
 
+// The scope tags of the api methods, a token scoped to an owner can only invoke the methods with a scope tag.
+const (
+	// ScopeAny methods do not touch deployments
+	ScopeAny = "any"
+	// ScopeDeployment methods only reach the deployments of the scope
+	ScopeDeployment = "deployment"
+	// ScopeOwner methods take the scope owner as their string parameters
+	ScopeOwner = "owner"
+)
+
+type authScopeKey struct{}
+
+// WithAuthScope returns a context carrying the scope of the caller token.
+func WithAuthScope(ctx context.Context, scope *types.AuthScope) context.Context {
+	return context.WithValue(ctx, authScopeKey{}, scope)
+}
+
+// GetAuthScope returns the scope of the caller token, nil for an unscoped token.
+func GetAuthScope(ctx context.Context) *types.AuthScope {
+	scope, _ := ctx.Value(authScopeKey{}).(*types.AuthScope)
+	return scope
+}
+
 func permissionedProxies(in, out interface{}) {
 	outs := GetInternalStructs(out)
 	for _, o := range outs {
 		auth.PermissionedProxy(AllPermissions, DefaultPerms, in, o)
+		scopedProxy(o)
+	}
+}
+
+// scopedProxy wraps the methods of the proxy with the checks of the caller token scope.
+func scopedProxy(out interface{}) {
+	rint := reflect.ValueOf(out).Elem()
+
+	for f := 0; f < rint.NumField(); f++ {
+		field := rint.Type().Field(f)
+		scopeTag := field.Tag.Get("scope")
+		next := reflect.ValueOf(rint.Field(f).Interface())
+
+		rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) (results []reflect.Value) {
+			ctx := args[0].Interface().(context.Context)
+			scope := GetAuthScope(ctx)
+			if scope == nil {
+				return next.Call(args)
+			}
+
+			if err := checkScope(scope, scopeTag, field.Name, args[1:]); err != nil {
+				rerr := reflect.ValueOf(&err).Elem()
+				if field.Type.NumOut() == 2 {
+					return []reflect.Value{reflect.Zero(field.Type.Out(0)), rerr}
+				}
+				return []reflect.Value{rerr}
+			}
+
+			results = next.Call(args)
+			if len(results) == 2 {
+				if deployments, ok := results[0].Interface().([]*types.Deployment); ok {
+					results[0] = reflect.ValueOf(scopedDeployments(scope, deployments))
+				}
+			}
+			return results
+		}))
+	}
+}
+
+// checkScope checks the parameters of the method against the scope, the owner of a deployment only known
+// by its id is checked by the implementation.
+func checkScope(scope *types.AuthScope, scopeTag string, method string, params []reflect.Value) error {
+	switch scopeTag {
+	case ScopeAny:
+		return nil
+	case ScopeDeployment, ScopeOwner:
+	default:
+		return xerrors.Errorf("token scoped to owner %s can not invoke '%s'", scope.Owner, method)
+	}
+
+	for _, param := range params {
+		switch p := param.Interface().(type) {
+		case *types.Deployment:
+			if p == nil {
+				continue
+			}
+			if p.Owner != "" && p.Owner != scope.Owner {
+				return xerrors.Errorf("deployment owner %s is out of the token scope", p.Owner)
+			}
+			if !scope.Allows(p.ID) {
+				return xerrors.Errorf("deployment %s is out of the token scope", p.ID)
+			}
+		case types.DeploymentID:
+			if !scope.Allows(p) {
+				return xerrors.Errorf("deployment %s is out of the token scope", p)
+			}
+		case *types.GetDeploymentOption:
+			if p == nil {
+				continue
+			}
+			if p.Owner == "" {
+				p.Owner = scope.Owner
+			}
+			if p.Owner != scope.Owner {
+				return xerrors.Errorf("owner %s is out of the token scope", p.Owner)
+			}
+			if p.DeploymentID != "" && !scope.Allows(p.DeploymentID) {
+				return xerrors.Errorf("deployment %s is out of the token scope", p.DeploymentID)
+			}
+		case string:
+			if scopeTag == ScopeOwner && p != scope.Owner {
+				return xerrors.Errorf("owner %s is out of the token scope", p)
+			}
+		}
+	}
+	return nil
+}
+
+// scopedDeployments returns the deployments of the list within the scope, the deployments of its owner
+// in the allow-list of the scope.
+func scopedDeployments(scope *types.AuthScope, deployments []*types.Deployment) []*types.Deployment {
+	out := make([]*types.Deployment, 0, len(deployments))
+	for _, deployment := range deployments {
+		if deployment.Owner == scope.Owner && scope.Allows(deployment.ID) {
+			out = append(out, deployment)
+		}
 	}
+	return out
 }
 
 func PermissionedManagerAPI(a Manager) Manager {
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+type scopedManager struct {
+	ManagerStub
+	deployments []*types.Deployment
+}
+
+func (m *scopedManager) GetDeploymentList(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.Deployment, error) {
+	var out []*types.Deployment
+	for _, deployment := range m.deployments {
+		if opt.Owner == "" || deployment.Owner == opt.Owner {
+			out = append(out, deployment)
+		}
+	}
+	return out, nil
+}
+
+func (m *scopedManager) GetLogs(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceLog, error) {
+	return []*types.ServiceLog{}, nil
+}
+
+func TestScopedProxy(t *testing.T) {
+	a := PermissionedManagerAPI(&scopedManager{deployments: []*types.Deployment{
+		{ID: "1", Owner: "alice"},
+		{ID: "2", Owner: "alice"},
+		{ID: "3", Owner: "bob"},
+	}})
+
+	ctx := auth.WithPerm(context.Background(), []auth.Permission{PermRead})
+	deployments, err := a.GetDeploymentList(ctx, &types.GetDeploymentOption{})
+	require.NoError(t, err)
+	require.Len(t, deployments, 3)
+
+	ctx = WithAuthScope(ctx, &types.AuthScope{Owner: "alice", Deployments: []types.DeploymentID{"2"}})
+	deployments, err = a.GetDeploymentList(ctx, &types.GetDeploymentOption{})
+	require.NoError(t, err)
+	require.Equal(t, []*types.Deployment{{ID: "2", Owner: "alice"}}, deployments)
+
+	_, err = a.GetDeploymentList(ctx, &types.GetDeploymentOption{Owner: "bob"})
+	require.Error(t, err)
+
+	_, err = a.GetLogs(ctx, &types.Deployment{ID: "2"})
+	require.NoError(t, err)
+	_, err = a.GetLogs(ctx, &types.Deployment{ID: "1"})
+	require.Error(t, err)
+
+	// methods without scope tag are out of reach of scoped tokens
+	_, err = a.GetProviderList(ctx, &types.GetProviderOption{})
+	require.ErrorContains(t, err, "can not invoke")
+}
@@ -4,6 +4,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/gnasnik/titan-container/api/types"
@@ -16,11 +17,11 @@ var ErrNotSupported = xerrors.New("method not supported")
 
 type CommonStruct struct {
 	Internal struct {
-		AuthNew func(p0 context.Context, p1 []auth.Permission) ([]byte, error) `perm:"admin"`
+		AuthNew func(p0 context.Context, p1 []auth.Permission, p2 *types.AuthScope) ([]byte, error) `perm:"admin"`
 
-		AuthVerify func(p0 context.Context, p1 string) ([]auth.Permission, error) `perm:"read"`
+		AuthVerify func(p0 context.Context, p1 string) ([]auth.Permission, error) `perm:"read" scope:"any"`
 
-		Closing func(p0 context.Context) (<-chan struct{}, error) `perm:"admin"`
+		Closing func(p0 context.Context) (<-chan struct{}, error) `perm:"admin" scope:"any"`
 
 		Discover func(p0 context.Context) (types.OpenRPCDocument, error) `perm:"admin"`
 
@@ -30,11 +31,11 @@ type CommonStruct struct {
 
 		LogSetLevel func(p0 context.Context, p1 string, p2 string) error `perm:"admin"`
 
-		Session func(p0 context.Context) (uuid.UUID, error) `perm:"admin"`
+		Session func(p0 context.Context) (uuid.UUID, error) `perm:"admin" scope:"any"`
 
 		Shutdown func(p0 context.Context) error `perm:"admin"`
 
-		Version func(p0 context.Context) (APIVersion, error) `perm:"read"`
+		Version func(p0 context.Context) (APIVersion, error) `perm:"read" scope:"any"`
 	}
 }
 
@@ -45,25 +46,55 @@ type ManagerStruct struct {
 	CommonStruct
 
 	Internal struct {
-		CloseDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
+		CloseDeployment func(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) `perm:"write" scope:"deployment"`
 
-		CreateDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
+		CreateDeployment func(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) `perm:"write" scope:"deployment"`
+
+		ExecDeployment func(p0 context.Context, p1 *types.Deployment, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) `perm:"admin"`
+
+		ExecInput func(p0 context.Context, p1 *types.Deployment, p2 *types.ExecInput) error `perm:"admin"`
+
+		GetDeploymentList func(p0 context.Context, p1 *types.GetDeploymentOption) ([]*types.Deployment, error) `perm:"read" scope:"deployment"`
+
+		GetDeploymentMetrics func(p0 context.Context, p1 *types.Deployment) (*types.DeploymentMetrics, error) `perm:"read" scope:"deployment"`
 
-		GetDeploymentList func(p0 context.Context, p1 *types.GetDeploymentOption) ([]*types.Deployment, error) `perm:"read"`
+		GetDeploymentRevisions func(p0 context.Context, p1 types.DeploymentID) ([]*types.DeploymentRevision, error) `perm:"read" scope:"deployment"`
 
-		GetEvents func(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceEvent, error) `perm:"read"`
+		GetDeploymentStateChanges func(p0 context.Context, p1 *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error) `perm:"read" scope:"deployment"`
 
-		GetLogs func(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceLog, error) `perm:"read"`
+		GetEvents func(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceEvent, error) `perm:"read" scope:"deployment"`
+
+		GetLogStream func(p0 context.Context, p1 *types.Deployment, p2 *types.LogOption) (<-chan *types.LogLine, error) `perm:"read" scope:"deployment"`
+
+		GetLogs func(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceLog, error) `perm:"read" scope:"deployment"`
+
+		GetOperation func(p0 context.Context, p1 types.OperationID) (*types.Operation, error) `perm:"read" scope:"deployment"`
 
 		GetProviderList func(p0 context.Context, p1 *types.GetProviderOption) ([]*types.Provider, error) `perm:"read"`
 
+		GetProviderStateChanges func(p0 context.Context, p1 *types.GetProviderOption) ([]*types.ProviderStateChange, error) `perm:"read"`
+
+		GetQuota func(p0 context.Context, p1 string) (*types.QuotaUsage, error) `perm:"read" scope:"owner"`
+
 		GetStatistics func(p0 context.Context, p1 types.ProviderID) (*types.ResourcesStatistics, error) `perm:"read"`
 
+		GetUsage func(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.Usage, error) `perm:"read" scope:"owner"`
+
 		ProviderConnect func(p0 context.Context, p1 string, p2 *types.Provider) error `perm:"admin"`
 
+		RollbackDeployment func(p0 context.Context, p1 types.DeploymentID, p2 int) (*types.Operation, error) `perm:"admin" scope:"deployment"`
+
+		ScaleDeployment func(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error `perm:"admin" scope:"deployment"`
+
 		SetProperties func(p0 context.Context, p1 *types.Properties) error `perm:"admin"`
 
-		UpdateDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
+		SetQuota func(p0 context.Context, p1 *types.Quota) error `perm:"admin"`
+
+		TopUpDeployment func(p0 context.Context, p1 types.DeploymentID, p2 float64, p3 time.Duration) error `perm:"admin"`
+
+		UpdateDeployment func(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) `perm:"write" scope:"deployment"`
+
+		WatchOperation func(p0 context.Context, p1 types.OperationID) (<-chan *types.Operation, error) `perm:"read" scope:"deployment"`
 	}
 }
 
@@ -73,37 +104,55 @@ type ManagerStub struct {
 
 type ProviderStruct struct {
 	Internal struct {
+		CheckCapacity func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
+
 		CloseDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
 
 		CreateDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
 
+		ExecDeployment func(p0 context.Context, p1 types.DeploymentID, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) `perm:"admin"`
+
+		ExecInput func(p0 context.Context, p1 *types.ExecInput) error `perm:"admin"`
+
+		GetAppliedDeployment func(p0 context.Context, p1 types.DeploymentID) (*types.Deployment, error) `perm:"read"`
+
 		GetDeployment func(p0 context.Context, p1 types.DeploymentID) (*types.Deployment, error) `perm:"read"`
 
+		GetDeploymentMetrics func(p0 context.Context, p1 types.DeploymentID) (*types.DeploymentMetrics, error) `perm:"read"`
+
 		GetEvents func(p0 context.Context, p1 types.DeploymentID) ([]*types.ServiceEvent, error) `perm:"read"`
 
+		GetLogStream func(p0 context.Context, p1 types.DeploymentID, p2 *types.LogOption) (<-chan *types.LogLine, error) `perm:"read"`
+
 		GetLogs func(p0 context.Context, p1 types.DeploymentID) ([]*types.ServiceLog, error) `perm:"read"`
 
 		GetStatistics func(p0 context.Context) (*types.ResourcesStatistics, error) `perm:"read"`
 
+		ListDeployments func(p0 context.Context) ([]*types.Deployment, error) `perm:"read"`
+
+		ScaleDeployment func(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error `perm:"admin"`
+
 		Session func(p0 context.Context) (uuid.UUID, error) `perm:"admin"`
 
 		UpdateDeployment func(p0 context.Context, p1 *types.Deployment) error `perm:"admin"`
 
 		Version func(p0 context.Context) (Version, error) `perm:"admin"`
+
+		WatchDeployments func(p0 context.Context) (<-chan *types.Deployment, error) `perm:"read"`
 	}
 }
 
 type ProviderStub struct {
 }
 
-func (s *CommonStruct) AuthNew(p0 context.Context, p1 []auth.Permission) ([]byte, error) {
+func (s *CommonStruct) AuthNew(p0 context.Context, p1 []auth.Permission, p2 *types.AuthScope) ([]byte, error) {
 	if s.Internal.AuthNew == nil {
 		return *new([]byte), ErrNotSupported
 	}
-	return s.Internal.AuthNew(p0, p1)
+	return s.Internal.AuthNew(p0, p1, p2)
 }
 
-func (s *CommonStub) AuthNew(p0 context.Context, p1 []auth.Permission) ([]byte, error) {
+func (s *CommonStub) AuthNew(p0 context.Context, p1 []auth.Permission, p2 *types.AuthScope) ([]byte, error) {
 	return *new([]byte), ErrNotSupported
 }
 
@@ -206,25 +255,47 @@ func (s *CommonStub) Version(p0 context.Context) (APIVersion, error) {
 	return *new(APIVersion), ErrNotSupported
 }
 
-func (s *ManagerStruct) CloseDeployment(p0 context.Context, p1 *types.Deployment) error {
+func (s *ManagerStruct) CloseDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
 	if s.Internal.CloseDeployment == nil {
-		return ErrNotSupported
+		return nil, ErrNotSupported
 	}
 	return s.Internal.CloseDeployment(p0, p1)
 }
 
-func (s *ManagerStub) CloseDeployment(p0 context.Context, p1 *types.Deployment) error {
-	return ErrNotSupported
+func (s *ManagerStub) CloseDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
+	return nil, ErrNotSupported
 }
 
-func (s *ManagerStruct) CreateDeployment(p0 context.Context, p1 *types.Deployment) error {
+func (s *ManagerStruct) CreateDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
 	if s.Internal.CreateDeployment == nil {
-		return ErrNotSupported
+		return nil, ErrNotSupported
 	}
 	return s.Internal.CreateDeployment(p0, p1)
 }
 
-func (s *ManagerStub) CreateDeployment(p0 context.Context, p1 *types.Deployment) error {
+func (s *ManagerStub) CreateDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ManagerStruct) ExecDeployment(p0 context.Context, p1 *types.Deployment, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	if s.Internal.ExecDeployment == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ExecDeployment(p0, p1, p2)
+}
+
+func (s *ManagerStub) ExecDeployment(p0 context.Context, p1 *types.Deployment, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ManagerStruct) ExecInput(p0 context.Context, p1 *types.Deployment, p2 *types.ExecInput) error {
+	if s.Internal.ExecInput == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ExecInput(p0, p1, p2)
+}
+
+func (s *ManagerStub) ExecInput(p0 context.Context, p1 *types.Deployment, p2 *types.ExecInput) error {
 	return ErrNotSupported
 }
 
@@ -239,6 +310,39 @@ func (s *ManagerStub) GetDeploymentList(p0 context.Context, p1 *types.GetDeploym
 	return *new([]*types.Deployment), ErrNotSupported
 }
 
+func (s *ManagerStruct) GetDeploymentMetrics(p0 context.Context, p1 *types.Deployment) (*types.DeploymentMetrics, error) {
+	if s.Internal.GetDeploymentMetrics == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetDeploymentMetrics(p0, p1)
+}
+
+func (s *ManagerStub) GetDeploymentMetrics(p0 context.Context, p1 *types.Deployment) (*types.DeploymentMetrics, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ManagerStruct) GetDeploymentRevisions(p0 context.Context, p1 types.DeploymentID) ([]*types.DeploymentRevision, error) {
+	if s.Internal.GetDeploymentRevisions == nil {
+		return *new([]*types.DeploymentRevision), ErrNotSupported
+	}
+	return s.Internal.GetDeploymentRevisions(p0, p1)
+}
+
+func (s *ManagerStub) GetDeploymentRevisions(p0 context.Context, p1 types.DeploymentID) ([]*types.DeploymentRevision, error) {
+	return *new([]*types.DeploymentRevision), ErrNotSupported
+}
+
+func (s *ManagerStruct) GetDeploymentStateChanges(p0 context.Context, p1 *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error) {
+	if s.Internal.GetDeploymentStateChanges == nil {
+		return *new([]*types.DeploymentStateChange), ErrNotSupported
+	}
+	return s.Internal.GetDeploymentStateChanges(p0, p1)
+}
+
+func (s *ManagerStub) GetDeploymentStateChanges(p0 context.Context, p1 *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error) {
+	return *new([]*types.DeploymentStateChange), ErrNotSupported
+}
+
 func (s *ManagerStruct) GetEvents(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceEvent, error) {
 	if s.Internal.GetEvents == nil {
 		return *new([]*types.ServiceEvent), ErrNotSupported
@@ -250,6 +354,17 @@ func (s *ManagerStub) GetEvents(p0 context.Context, p1 *types.Deployment) ([]*ty
 	return *new([]*types.ServiceEvent), ErrNotSupported
 }
 
+func (s *ManagerStruct) GetLogStream(p0 context.Context, p1 *types.Deployment, p2 *types.LogOption) (<-chan *types.LogLine, error) {
+	if s.Internal.GetLogStream == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetLogStream(p0, p1, p2)
+}
+
+func (s *ManagerStub) GetLogStream(p0 context.Context, p1 *types.Deployment, p2 *types.LogOption) (<-chan *types.LogLine, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ManagerStruct) GetLogs(p0 context.Context, p1 *types.Deployment) ([]*types.ServiceLog, error) {
 	if s.Internal.GetLogs == nil {
 		return *new([]*types.ServiceLog), ErrNotSupported
@@ -261,6 +376,17 @@ func (s *ManagerStub) GetLogs(p0 context.Context, p1 *types.Deployment) ([]*type
 	return *new([]*types.ServiceLog), ErrNotSupported
 }
 
+func (s *ManagerStruct) GetOperation(p0 context.Context, p1 types.OperationID) (*types.Operation, error) {
+	if s.Internal.GetOperation == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetOperation(p0, p1)
+}
+
+func (s *ManagerStub) GetOperation(p0 context.Context, p1 types.OperationID) (*types.Operation, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ManagerStruct) GetProviderList(p0 context.Context, p1 *types.GetProviderOption) ([]*types.Provider, error) {
 	if s.Internal.GetProviderList == nil {
 		return *new([]*types.Provider), ErrNotSupported
@@ -272,6 +398,28 @@ func (s *ManagerStub) GetProviderList(p0 context.Context, p1 *types.GetProviderO
 	return *new([]*types.Provider), ErrNotSupported
 }
 
+func (s *ManagerStruct) GetProviderStateChanges(p0 context.Context, p1 *types.GetProviderOption) ([]*types.ProviderStateChange, error) {
+	if s.Internal.GetProviderStateChanges == nil {
+		return *new([]*types.ProviderStateChange), ErrNotSupported
+	}
+	return s.Internal.GetProviderStateChanges(p0, p1)
+}
+
+func (s *ManagerStub) GetProviderStateChanges(p0 context.Context, p1 *types.GetProviderOption) ([]*types.ProviderStateChange, error) {
+	return *new([]*types.ProviderStateChange), ErrNotSupported
+}
+
+func (s *ManagerStruct) GetQuota(p0 context.Context, p1 string) (*types.QuotaUsage, error) {
+	if s.Internal.GetQuota == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetQuota(p0, p1)
+}
+
+func (s *ManagerStub) GetQuota(p0 context.Context, p1 string) (*types.QuotaUsage, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ManagerStruct) GetStatistics(p0 context.Context, p1 types.ProviderID) (*types.ResourcesStatistics, error) {
 	if s.Internal.GetStatistics == nil {
 		return nil, ErrNotSupported
@@ -283,6 +431,17 @@ func (s *ManagerStub) GetStatistics(p0 context.Context, p1 types.ProviderID) (*t
 	return nil, ErrNotSupported
 }
 
+func (s *ManagerStruct) GetUsage(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.Usage, error) {
+	if s.Internal.GetUsage == nil {
+		return *new([]*types.Usage), ErrNotSupported
+	}
+	return s.Internal.GetUsage(p0, p1, p2, p3)
+}
+
+func (s *ManagerStub) GetUsage(p0 context.Context, p1 string, p2 time.Time, p3 time.Time) ([]*types.Usage, error) {
+	return *new([]*types.Usage), ErrNotSupported
+}
+
 func (s *ManagerStruct) ProviderConnect(p0 context.Context, p1 string, p2 *types.Provider) error {
 	if s.Internal.ProviderConnect == nil {
 		return ErrNotSupported
@@ -294,6 +453,28 @@ func (s *ManagerStub) ProviderConnect(p0 context.Context, p1 string, p2 *types.P
 	return ErrNotSupported
 }
 
+func (s *ManagerStruct) RollbackDeployment(p0 context.Context, p1 types.DeploymentID, p2 int) (*types.Operation, error) {
+	if s.Internal.RollbackDeployment == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.RollbackDeployment(p0, p1, p2)
+}
+
+func (s *ManagerStub) RollbackDeployment(p0 context.Context, p1 types.DeploymentID, p2 int) (*types.Operation, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ManagerStruct) ScaleDeployment(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error {
+	if s.Internal.ScaleDeployment == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ScaleDeployment(p0, p1, p2, p3)
+}
+
+func (s *ManagerStub) ScaleDeployment(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error {
+	return ErrNotSupported
+}
+
 func (s *ManagerStruct) SetProperties(p0 context.Context, p1 *types.Properties) error {
 	if s.Internal.SetProperties == nil {
 		return ErrNotSupported
@@ -305,14 +486,58 @@ func (s *ManagerStub) SetProperties(p0 context.Context, p1 *types.Properties) er
 	return ErrNotSupported
 }
 
-func (s *ManagerStruct) UpdateDeployment(p0 context.Context, p1 *types.Deployment) error {
-	if s.Internal.UpdateDeployment == nil {
+func (s *ManagerStruct) SetQuota(p0 context.Context, p1 *types.Quota) error {
+	if s.Internal.SetQuota == nil {
 		return ErrNotSupported
 	}
+	return s.Internal.SetQuota(p0, p1)
+}
+
+func (s *ManagerStub) SetQuota(p0 context.Context, p1 *types.Quota) error {
+	return ErrNotSupported
+}
+
+func (s *ManagerStruct) TopUpDeployment(p0 context.Context, p1 types.DeploymentID, p2 float64, p3 time.Duration) error {
+	if s.Internal.TopUpDeployment == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.TopUpDeployment(p0, p1, p2, p3)
+}
+
+func (s *ManagerStub) TopUpDeployment(p0 context.Context, p1 types.DeploymentID, p2 float64, p3 time.Duration) error {
+	return ErrNotSupported
+}
+
+func (s *ManagerStruct) UpdateDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
+	if s.Internal.UpdateDeployment == nil {
+		return nil, ErrNotSupported
+	}
 	return s.Internal.UpdateDeployment(p0, p1)
 }
 
-func (s *ManagerStub) UpdateDeployment(p0 context.Context, p1 *types.Deployment) error {
+func (s *ManagerStub) UpdateDeployment(p0 context.Context, p1 *types.Deployment) (*types.Operation, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ManagerStruct) WatchOperation(p0 context.Context, p1 types.OperationID) (<-chan *types.Operation, error) {
+	if s.Internal.WatchOperation == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.WatchOperation(p0, p1)
+}
+
+func (s *ManagerStub) WatchOperation(p0 context.Context, p1 types.OperationID) (<-chan *types.Operation, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ProviderStruct) CheckCapacity(p0 context.Context, p1 *types.Deployment) error {
+	if s.Internal.CheckCapacity == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.CheckCapacity(p0, p1)
+}
+
+func (s *ProviderStub) CheckCapacity(p0 context.Context, p1 *types.Deployment) error {
 	return ErrNotSupported
 }
 
@@ -338,6 +563,39 @@ func (s *ProviderStub) CreateDeployment(p0 context.Context, p1 *types.Deployment
 	return ErrNotSupported
 }
 
+func (s *ProviderStruct) ExecDeployment(p0 context.Context, p1 types.DeploymentID, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	if s.Internal.ExecDeployment == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ExecDeployment(p0, p1, p2)
+}
+
+func (s *ProviderStub) ExecDeployment(p0 context.Context, p1 types.DeploymentID, p2 *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *ProviderStruct) ExecInput(p0 context.Context, p1 *types.ExecInput) error {
+	if s.Internal.ExecInput == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ExecInput(p0, p1)
+}
+
+func (s *ProviderStub) ExecInput(p0 context.Context, p1 *types.ExecInput) error {
+	return ErrNotSupported
+}
+
+func (s *ProviderStruct) GetAppliedDeployment(p0 context.Context, p1 types.DeploymentID) (*types.Deployment, error) {
+	if s.Internal.GetAppliedDeployment == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetAppliedDeployment(p0, p1)
+}
+
+func (s *ProviderStub) GetAppliedDeployment(p0 context.Context, p1 types.DeploymentID) (*types.Deployment, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ProviderStruct) GetDeployment(p0 context.Context, p1 types.DeploymentID) (*types.Deployment, error) {
 	if s.Internal.GetDeployment == nil {
 		return nil, ErrNotSupported
@@ -349,6 +607,17 @@ func (s *ProviderStub) GetDeployment(p0 context.Context, p1 types.DeploymentID)
 	return nil, ErrNotSupported
 }
 
+func (s *ProviderStruct) GetDeploymentMetrics(p0 context.Context, p1 types.DeploymentID) (*types.DeploymentMetrics, error) {
+	if s.Internal.GetDeploymentMetrics == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetDeploymentMetrics(p0, p1)
+}
+
+func (s *ProviderStub) GetDeploymentMetrics(p0 context.Context, p1 types.DeploymentID) (*types.DeploymentMetrics, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ProviderStruct) GetEvents(p0 context.Context, p1 types.DeploymentID) ([]*types.ServiceEvent, error) {
 	if s.Internal.GetEvents == nil {
 		return *new([]*types.ServiceEvent), ErrNotSupported
@@ -360,6 +629,17 @@ func (s *ProviderStub) GetEvents(p0 context.Context, p1 types.DeploymentID) ([]*
 	return *new([]*types.ServiceEvent), ErrNotSupported
 }
 
+func (s *ProviderStruct) GetLogStream(p0 context.Context, p1 types.DeploymentID, p2 *types.LogOption) (<-chan *types.LogLine, error) {
+	if s.Internal.GetLogStream == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GetLogStream(p0, p1, p2)
+}
+
+func (s *ProviderStub) GetLogStream(p0 context.Context, p1 types.DeploymentID, p2 *types.LogOption) (<-chan *types.LogLine, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *ProviderStruct) GetLogs(p0 context.Context, p1 types.DeploymentID) ([]*types.ServiceLog, error) {
 	if s.Internal.GetLogs == nil {
 		return *new([]*types.ServiceLog), ErrNotSupported
@@ -382,6 +662,28 @@ func (s *ProviderStub) GetStatistics(p0 context.Context) (*types.ResourcesStatis
 	return nil, ErrNotSupported
 }
 
+func (s *ProviderStruct) ListDeployments(p0 context.Context) ([]*types.Deployment, error) {
+	if s.Internal.ListDeployments == nil {
+		return *new([]*types.Deployment), ErrNotSupported
+	}
+	return s.Internal.ListDeployments(p0)
+}
+
+func (s *ProviderStub) ListDeployments(p0 context.Context) ([]*types.Deployment, error) {
+	return *new([]*types.Deployment), ErrNotSupported
+}
+
+func (s *ProviderStruct) ScaleDeployment(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error {
+	if s.Internal.ScaleDeployment == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ScaleDeployment(p0, p1, p2, p3)
+}
+
+func (s *ProviderStub) ScaleDeployment(p0 context.Context, p1 types.DeploymentID, p2 string, p3 int) error {
+	return ErrNotSupported
+}
+
 func (s *ProviderStruct) Session(p0 context.Context) (uuid.UUID, error) {
 	if s.Internal.Session == nil {
 		return *new(uuid.UUID), ErrNotSupported
@@ -415,6 +717,17 @@ func (s *ProviderStub) Version(p0 context.Context) (Version, error) {
 	return *new(Version), ErrNotSupported
 }
 
+func (s *ProviderStruct) WatchDeployments(p0 context.Context) (<-chan *types.Deployment, error) {
+	if s.Internal.WatchDeployments == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.WatchDeployments(p0)
+}
+
+func (s *ProviderStub) WatchDeployments(p0 context.Context) (<-chan *types.Deployment, error) {
+	return nil, ErrNotSupported
+}
+
 var _ Common = new(CommonStruct)
 var _ Manager = new(ManagerStruct)
 var _ Provider = new(ProviderStruct)
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// AuthScope restricts an api token to the deployments of an owner.
+type AuthScope struct {
+	Owner string
+	// Deployments optionally allows the token to reach only these deployments of the owner
+	Deployments []DeploymentID `json:",omitempty"`
+}
+
+// Allows reports whether the deployment is within the deployments the scope is restricted to.
+func (s *AuthScope) Allows(id DeploymentID) bool {
+	if len(s.Deployments) == 0 {
+		return true
+	}
+
+	for _, allowed := range s.Deployments {
+		if allowed == id {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyType is the type of the key an owner signs with.
+type KeyType string
+
+const (
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+	KeyTypeEd25519   KeyType = "ed25519"
+)
+
+// OwnerOperation is the deployment operation an owner signature authorizes.
+type OwnerOperation string
+
+const (
+	OwnerOperationCreate  OwnerOperation = "create"
+	OwnerOperationUpdate  OwnerOperation = "update"
+	OwnerOperationClose   OwnerOperation = "close"
+	OwnerOperationLogs    OwnerOperation = "logs"
+	OwnerOperationEvents  OwnerOperation = "events"
+	OwnerOperationMetrics OwnerOperation = "metrics"
+	OwnerOperationExec    OwnerOperation = "exec"
+)
+
+// OwnerSignature proves that the caller holds the key of the deployment owner address.
+type OwnerSignature struct {
+	KeyType   KeyType
+	PublicKey []byte
+	Signature []byte
+	// Timestamp is the unix time the message was signed at, the manager rejects stale signatures
+	Timestamp int64
+	// Nonce is chosen at random by the signer, the manager accepts a nonce of an owner only once
+	Nonce string
+}
+
+// OwnerSignMessage returns the message the owner signs to authorize the operation on the deployment,
+// the deployment id is empty for a create. The message holds the hash of the payload the operation
+// is requested with: the deployment of a create or an update, the options of a log stream or an exec.
+func OwnerSignMessage(op OwnerOperation, owner string, id DeploymentID, timestamp int64, nonce string, payload interface{}) ([]byte, error) {
+	hash, err := ownerPayloadHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("titan-container:%s:%s:%s:%d:%s:%x", op, owner, id, timestamp, nonce, hash)), nil
+}
+
+// ownerPayloadHash returns the sha256 of the json encoding of the payload, a deployment is hashed without its signature.
+func ownerPayloadHash(payload interface{}) ([]byte, error) {
+	if deployment, ok := payload.(*Deployment); ok && deployment != nil {
+		unsigned := *deployment
+		unsigned.Signature = nil
+		payload = &unsigned
+	}
+
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(buf)
+	return hash[:], nil
+}
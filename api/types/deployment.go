@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -12,26 +13,87 @@ type DeploymentID string
 
 type DeploymentState int
 
+// The values of the states are persisted, new states are appended.
 const (
-	DeploymentStateActive DeploymentState = iota + 1
+	// DeploymentStateRunning is a deployment with all the replicas of its services available
+	DeploymentStateRunning DeploymentState = iota + 1
+	// DeploymentStateInActive is a deployment whose provider is offline
 	DeploymentStateInActive
+	// DeploymentStateClose is a deployment removed from its provider
 	DeploymentStateClose
+	// DeploymentStatePending is a deployment not applied to its provider yet or with no pod running yet
+	DeploymentStatePending
+	// DeploymentStateDeploying is a deployment whose rollout is in progress
+	DeploymentStateDeploying
+	// DeploymentStateDegraded is a deployment that ran and lost some of its available replicas
+	DeploymentStateDegraded
+	// DeploymentStateFailed is a deployment whose rollout failed to make progress
+	DeploymentStateFailed
+	// DeploymentStateClosing is a deployment being removed from its provider
+	DeploymentStateClosing
 )
 
 func DeploymentStateString(state DeploymentState) string {
 	switch state {
-	case DeploymentStateActive:
-		return "Active"
+	case DeploymentStateRunning:
+		return "Running"
 	case DeploymentStateInActive:
 		return "InActive"
 	case DeploymentStateClose:
 		return "Deleted"
+	case DeploymentStatePending:
+		return "Pending"
+	case DeploymentStateDeploying:
+		return "Deploying"
+	case DeploymentStateDegraded:
+		return "Degraded"
+	case DeploymentStateFailed:
+		return "Failed"
+	case DeploymentStateClosing:
+		return "Closing"
 	default:
 		return "Unknown"
 	}
 }
 
-var AllDeploymentStates = []DeploymentState{DeploymentStateActive, DeploymentStateInActive, DeploymentStateClose}
+// ParseDeploymentState returns the state named by s, the names are case insensitive.
+func ParseDeploymentState(s string) (DeploymentState, error) {
+	for _, state := range AllDeploymentStates {
+		if strings.EqualFold(DeploymentStateString(state), s) {
+			return state, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown deployment state %q", s)
+}
+
+var AllDeploymentStates = []DeploymentState{
+	DeploymentStatePending,
+	DeploymentStateDeploying,
+	DeploymentStateRunning,
+	DeploymentStateDegraded,
+	DeploymentStateFailed,
+	DeploymentStateInActive,
+	DeploymentStateClosing,
+	DeploymentStateClose,
+}
+
+// ActiveDeploymentStates are the states of the deployments expected to run on their provider.
+var ActiveDeploymentStates = []DeploymentState{
+	DeploymentStatePending,
+	DeploymentStateDeploying,
+	DeploymentStateRunning,
+	DeploymentStateDegraded,
+	DeploymentStateFailed,
+}
+
+// DeploymentStateChange records a transition of the deployment state
+type DeploymentStateChange struct {
+	ID           int64           `db:"id"`
+	DeploymentID DeploymentID    `db:"deployment_id"`
+	State        DeploymentState `db:"state"`
+	Reason       string          `db:"reason"`
+	CreatedAt    time.Time       `db:"created_at"`
+}
 
 type DeploymentType int
 
@@ -40,13 +102,18 @@ const (
 )
 
 type Deployment struct {
-	ID        DeploymentID    `db:"id"`
-	Name      string          `db:"name"`
-	Owner     string          `db:"owner"`
-	State     DeploymentState `db:"state"`
-	Version   []byte          `db:"version"`
-	Authority bool            `db:"authority"`
-	Services  []*Service
+	ID          DeploymentID    `db:"id"`
+	Name        string          `db:"name"`
+	Owner       string          `db:"owner"`
+	State       DeploymentState `db:"state"`
+	StateReason string          `db:"state_reason"`
+	Version     []byte          `db:"version"`
+	Authority   bool            `db:"authority"`
+	Services    []*Service
+	// RegistryCredentials are write only, they are never returned by the deployment list
+	RegistryCredentials []*RegistryCredential `db:"-"`
+	// Signature authorizes the operation for the owner when the api token is not scoped to the owner
+	Signature *OwnerSignature `db:"-"`
 
 	// Internal
 	Type             DeploymentType `db:"type"`
@@ -57,12 +124,23 @@ type Deployment struct {
 	CreatedAt        time.Time      `db:"created_at"`
 	UpdatedAt        time.Time      `db:"updated_at"`
 	ProviderExposeIP string         `db:"provider_expose_ip"`
+	// EncryptedRegistryCredentials is the encrypted form of RegistryCredentials stored by the manager
+	EncryptedRegistryCredentials []byte `db:"registry_credentials" json:"-"`
+}
+
+// RegistryCredential authenticates the image pulls from a private registry
+type RegistryCredential struct {
+	Server   string
+	Username string
+	// Password or access token
+	Password string
 }
 
 type ReplicasStatus struct {
 	TotalReplicas     int
 	ReadyReplicas     int
 	AvailableReplicas int
+	Health            ServiceHealth
 }
 
 type Service struct {
@@ -73,6 +151,13 @@ type Service struct {
 	Status       ReplicasStatus `db:"status"`
 	ErrorMessage string         `db:"error_message"`
 	Arguments    Arguments      `db:"arguments"`
+	Replicas     int            `db:"replicas"`
+	Volumes      Volumes        `db:"volumes"`
+	Probes       Probes         `db:"probes"`
+	URLs         []string       `db:"-"`
+	// The values of the secrets and config files are write only, they are redacted from the deployment list
+	Secrets     []Secret     `db:"-"`
+	ConfigFiles []ConfigFile `db:"-"`
 	ComputeResources
 
 	// Internal
@@ -80,6 +165,8 @@ type Service struct {
 	DeploymentID DeploymentID `db:"deployment_id"`
 	CreatedAt    time.Time    `db:"created_at"`
 	UpdatedAt    time.Time    `db:"updated_at"`
+	// EncryptedConfig is the encrypted form of Secrets and ConfigFiles stored by the manager
+	EncryptedConfig []byte `db:"encrypted_config" json:"-"`
 }
 
 type Env map[string]string
@@ -118,6 +205,34 @@ func (a Arguments) Scan(value interface{}) error {
 	return nil
 }
 
+// Volume is a persistent volume mounted into the service containers, the data survives pod restarts
+type Volume struct {
+	Name string
+	// Size in MB
+	Size         int64
+	MountPath    string
+	ReadOnly     bool
+	StorageClass string
+}
+
+type Volumes []Volume
+
+func (v Volumes) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+func (v *Volumes) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, v)
+}
+
 type Protocol string
 
 const (
@@ -129,6 +244,8 @@ type Port struct {
 	Protocol   Protocol `db:"protocol"`
 	Port       int      `db:"port"`
 	ExposePort int      `db:"expose_port"`
+	// custom hosts routed to the port through the ingress
+	Hosts []string `db:"hosts"`
 }
 
 type Ports []Port
@@ -152,6 +269,55 @@ func (a Ports) Scan(value interface{}) error {
 	return nil
 }
 
+// Secret is a sensitive value of a service, exposed to the containers as an environment variable,
+// a file mounted at MountPath or both
+type Secret struct {
+	Name      string
+	Value     string
+	Env       string
+	MountPath string
+}
+
+// ConfigFile is a configuration of a service, exposed to the containers as a file mounted at MountPath,
+// an environment variable or both
+type ConfigFile struct {
+	Name      string
+	Content   string
+	Env       string
+	MountPath string
+}
+
+// DeploymentSpec is the desired state of a deployment recorded in a revision
+type DeploymentSpec struct {
+	Name      string
+	Authority bool
+	Services  []*Service
+	// EncryptedConfigs holds the encrypted secrets and config files of the services by service name,
+	// the services of the spec only keep the redacted ones
+	EncryptedConfigs map[string][]byte
+}
+
+func (s DeploymentSpec) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *DeploymentSpec) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, s)
+}
+
+// DeploymentRevision is a numbered snapshot of the deployment spec, recorded on every create and update
+type DeploymentRevision struct {
+	ID           int64          `db:"id"`
+	DeploymentID DeploymentID   `db:"deployment_id"`
+	Revision     int            `db:"revision"`
+	Spec         DeploymentSpec `db:"spec"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
 type GetDeploymentOption struct {
 	Owner        string
 	DeploymentID DeploymentID
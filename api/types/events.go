@@ -1,8 +1,35 @@
 package types
 
-type Event string
+import "time"
+
+// Event is a kubernetes event reported for a pod of the service
+type Event struct {
+	// Type is Normal or Warning
+	Type    string
+	Reason  string
+	Message string
+	// Probe is the kind of the failed probe when the event reports a probe failure
+	Probe   ProbeKind
+	PodName string
+	Count   int
+	Time    time.Time
+}
 
 type ServiceEvent struct {
 	ServiceName string
 	Events      []Event
 }
+
+// ServiceHealth summarizes the state of the pods of a service
+type ServiceHealth string
+
+const (
+	// ServiceHealthHealthy all the pods are running and ready
+	ServiceHealthHealthy ServiceHealth = "Healthy"
+	// ServiceHealthStarting some pods are scheduled, pulling images or waiting for the startup probe
+	ServiceHealthStarting ServiceHealth = "Starting"
+	// ServiceHealthUnhealthy some pods are running but fail their readiness or liveness probe
+	ServiceHealthUnhealthy ServiceHealth = "Unhealthy"
+	// ServiceHealthCrashing some containers exit and are restarted in back-off
+	ServiceHealthCrashing ServiceHealth = "Crashing"
+)
@@ -0,0 +1,36 @@
+package types
+
+// ExecOption describes a command executed in a deployment container
+type ExecOption struct {
+	// SessionID identifies the exec session in ExecInput calls, generated by the caller
+	SessionID string
+	// ServiceName is the service to execute the command in, it can be empty if the deployment has only one service
+	ServiceName string
+	Command     []string
+	Stdin       bool
+	TTY         bool
+	Size        *TerminalSize
+}
+
+// TerminalSize is the size of the terminal attached to an exec session
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// ExecInput is sent by the caller to an exec session
+type ExecInput struct {
+	SessionID  string
+	Data       []byte
+	Resize     *TerminalSize
+	CloseStdin bool
+}
+
+// ExecOutput is sent by an exec session to the caller, the last output of a session has Exited set
+type ExecOutput struct {
+	Stdout   []byte
+	Stderr   []byte
+	Exited   bool
+	ExitCode int
+	Error    string
+}
@@ -1,8 +1,29 @@
 package types
 
+import "time"
+
 type Log string
 
 type ServiceLog struct {
 	ServiceName string
 	Logs        []Log
 }
+
+// LogOption filters the logs returned by a log stream
+type LogOption struct {
+	// ServiceName only returns the logs of the service if set
+	ServiceName string
+	// TailLines is the number of lines from the end of the logs to start with, all logs if zero
+	TailLines int64
+	// SinceTime only returns the logs after the time if set
+	SinceTime time.Time
+	// Follow keeps the stream open and sends new logs as they are written
+	Follow bool
+}
+
+// LogLine is a single log line of a service pod
+type LogLine struct {
+	ServiceName string
+	PodName     string
+	Line        Log
+}
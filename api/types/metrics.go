@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// ResourceUsage is an amount of compute resources in the units of ComputeResources,
+// the cpu in cores and the memory in MB
+type ResourceUsage struct {
+	CPU    float64
+	Memory int64
+}
+
+// PodMetrics is the current resource usage of a pod of the service
+type PodMetrics struct {
+	PodName string
+	Usage   ResourceUsage
+	// Time is the end of the window the usage was measured over
+	Time time.Time
+}
+
+// ServiceMetrics is the resource usage of the pods of a service, the requests and the limits are per pod
+type ServiceMetrics struct {
+	ServiceName string
+	// Usage is the sum of the usage of the pods
+	Usage    ResourceUsage
+	Requests ResourceUsage
+	Limits   ResourceUsage
+	Pods     []*PodMetrics
+}
+
+type DeploymentMetrics struct {
+	ID       DeploymentID
+	Services []*ServiceMetrics
+}
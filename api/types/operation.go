@@ -0,0 +1,52 @@
+package types
+
+import "time"
+
+type OperationID string
+
+// OperationType is the kind of change an operation applies to a deployment.
+type OperationType string
+
+const (
+	OperationCreate OperationType = "create"
+	OperationUpdate OperationType = "update"
+	OperationClose  OperationType = "close"
+)
+
+type OperationState int
+
+const (
+	OperationStateRunning OperationState = iota + 1
+	OperationStateSucceeded
+	OperationStateFailed
+)
+
+func OperationStateString(state OperationState) string {
+	switch state {
+	case OperationStateRunning:
+		return "Running"
+	case OperationStateSucceeded:
+		return "Succeeded"
+	case OperationStateFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Operation tracks a change of a deployment applied to its provider in the background.
+type Operation struct {
+	ID           OperationID    `db:"id"`
+	DeploymentID DeploymentID   `db:"deployment_id"`
+	Type         OperationType  `db:"type"`
+	State        OperationState `db:"state"`
+	// Error is the reason of a failed operation
+	Error     string    `db:"error"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Done reports whether the operation finished.
+func (o *Operation) Done() bool {
+	return o.State == OperationStateSucceeded || o.State == OperationStateFailed
+}
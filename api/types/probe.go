@@ -0,0 +1,71 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// ProbeKind is the kind of a health check of the service containers
+type ProbeKind string
+
+const (
+	// ProbeLiveness restarts the container when it fails
+	ProbeLiveness ProbeKind = "liveness"
+	// ProbeReadiness removes the container from the service endpoints when it fails
+	ProbeReadiness ProbeKind = "readiness"
+	// ProbeStartup holds the other probes until the container has started
+	ProbeStartup ProbeKind = "startup"
+)
+
+// Probe is a health check of the service containers, exactly one of HTTPGet, TCPSocket and Exec must be set.
+// The zero values of the thresholds fall back to the kubernetes defaults.
+type Probe struct {
+	HTTPGet   *HTTPGetProbe
+	TCPSocket *TCPSocketProbe
+	Exec      *ExecProbe
+
+	InitialDelaySeconds int
+	PeriodSeconds       int
+	TimeoutSeconds      int
+	SuccessThreshold    int
+	FailureThreshold    int
+}
+
+// HTTPGetProbe succeeds when a GET of the path on the container port returns a status in [200, 400)
+type HTTPGetProbe struct {
+	Path string
+	Port int
+}
+
+// TCPSocketProbe succeeds when a connection to the container port can be opened
+type TCPSocketProbe struct {
+	Port int
+}
+
+// ExecProbe succeeds when the command exits with 0 in the container
+type ExecProbe struct {
+	Command []string
+}
+
+type Probes struct {
+	Liveness  *Probe
+	Readiness *Probe
+	Startup   *Probe
+}
+
+func (p Probes) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *Probes) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, p)
+}
@@ -31,10 +31,20 @@ type Provider struct {
 	HostURI   string        `db:"host_uri"`
 	IP        string        `db:"ip"`
 	State     ProviderState `db:"state"`
+	LastSeen  time.Time     `db:"last_seen"`
 	CreatedAt time.Time     `db:"created_at"`
 	UpdatedAt time.Time     `db:"updated_at"`
 }
 
+// ProviderStateChange records a transition of the provider state
+type ProviderStateChange struct {
+	ID         int64         `db:"id"`
+	ProviderID ProviderID    `db:"provider_id"`
+	State      ProviderState `db:"state"`
+	Reason     string        `db:"reason"`
+	CreatedAt  time.Time     `db:"created_at"`
+}
+
 type GetProviderOption struct {
 	Owner string
 	ID    ProviderID
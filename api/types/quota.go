@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// Quota limits the deployments an owner can run, a zero limit is unlimited.
+// Memory and storage are in MB like the ComputeResources of a service.
+type Quota struct {
+	Owner          string  `db:"owner"`
+	MaxDeployments int     `db:"max_deployments"`
+	MaxCPU         float64 `db:"max_cpu"`
+	MaxMemory      int64   `db:"max_memory"`
+	MaxStorage     int64   `db:"max_storage"`
+
+	// Internal
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// QuotaUsage is the quota of an owner with the resources used by its deployments that are not closed.
+type QuotaUsage struct {
+	Quota       Quota
+	Deployments int
+	Used        ComputeResources
+}
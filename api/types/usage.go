@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// UsageRecord is the resources a deployment was allocated and used between two samples of the manager,
+// the cpu is in core-seconds, the memory and the storage in byte-seconds.
+type UsageRecord struct {
+	ID                    int64        `db:"id"`
+	DeploymentID          DeploymentID `db:"deployment_id"`
+	Owner                 string       `db:"owner"`
+	ProviderID            ProviderID   `db:"provider_id"`
+	StartTime             time.Time    `db:"start_time"`
+	EndTime               time.Time    `db:"end_time"`
+	CPUSeconds            float64      `db:"cpu_seconds"`
+	MemoryByteSeconds     float64      `db:"memory_byte_seconds"`
+	StorageByteSeconds    float64      `db:"storage_byte_seconds"`
+	UsedCPUSeconds        float64      `db:"used_cpu_seconds"`
+	UsedMemoryByteSeconds float64      `db:"used_memory_byte_seconds"`
+}
+
+// Usage is the usage of a deployment aggregated over a day in UTC, in the units of UsageRecord.
+type Usage struct {
+	Day                   string       `db:"day"`
+	DeploymentID          DeploymentID `db:"deployment_id"`
+	Owner                 string       `db:"owner"`
+	ProviderID            ProviderID   `db:"provider_id"`
+	CPUSeconds            float64      `db:"cpu_seconds"`
+	MemoryByteSeconds     float64      `db:"memory_byte_seconds"`
+	StorageByteSeconds    float64      `db:"storage_byte_seconds"`
+	UsedCPUSeconds        float64      `db:"used_cpu_seconds"`
+	UsedMemoryByteSeconds float64      `db:"used_memory_byte_seconds"`
+}
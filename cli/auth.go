@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -29,6 +30,14 @@ var AuthCreateAdminToken = &cli.Command{
 			Name:  "perm",
 			Usage: "permission to assign to the token, one of: web, provider,admin",
 		},
+		&cli.StringFlag{
+			Name:  "owner",
+			Usage: "scope the token to the deployments of the owner address",
+		},
+		&cli.StringSliceFlag{
+			Name:  "deployment",
+			Usage: "only allow the owner scoped token to reach the deployment, can be repeated",
+		},
 	},
 
 	Action: func(cctx *cli.Context) error {
@@ -56,7 +65,17 @@ var AuthCreateAdminToken = &cli.Command{
 			return fmt.Errorf("--perm flag has to be one of: %s", api.AllPermissions)
 		}
 
-		token, err := napi.AuthNew(ctx, api.AllPermissions[:idx])
+		var scope *types.AuthScope
+		if cctx.String("owner") != "" {
+			scope = &types.AuthScope{Owner: cctx.String("owner")}
+			for _, id := range cctx.StringSlice("deployment") {
+				scope.Deployments = append(scope.Deployments, types.DeploymentID(id))
+			}
+		} else if cctx.IsSet("deployment") {
+			return xerrors.New("--deployment requires --owner")
+		}
+
+		token, err := napi.AuthNew(ctx, api.AllPermissions[:idx], scope)
 		if err != nil {
 			return err
 		}
@@ -103,7 +122,7 @@ var AuthAPIInfoToken = &cli.Command{
 			return fmt.Errorf("--perm flag has to be one of: %s", api.AllPermissions)
 		}
 
-		token, err := napi.AuthNew(ctx, api.AllPermissions[:idx])
+		token, err := napi.AuthNew(ctx, api.AllPermissions[:idx], nil)
 		if err != nil {
 			return err
 		}
@@ -1,18 +1,27 @@
 package cli
 
 import (
-	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	"github.com/docker/go-units"
 	"github.com/gnasnik/titan-container/api"
 	"github.com/gnasnik/titan-container/api/types"
 	"github.com/gnasnik/titan-container/lib/tablewriter"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 	"os"
+	"path/filepath"
 	"sigs.k8s.io/yaml"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var defaultDateTimeLayout = "2006-01-02 15:04:05"
@@ -20,22 +29,51 @@ var defaultDateTimeLayout = "2006-01-02 15:04:05"
 var deploymentCmds = &cli.Command{
 	Name:  "deployment",
 	Usage: "Manager deployment",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "owner-key",
+			Usage:   "file with the hex encoded private key of the owner, signs the deployment operations",
+			EnvVars: []string{"TITAN_OWNER_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "owner-key-type",
+			Usage:   "the type of the owner key, one of: secp256k1, ed25519",
+			EnvVars: []string{"TITAN_OWNER_KEY_TYPE"},
+			Value:   string(types.KeyTypeSecp256k1),
+		},
+	},
 	Subcommands: []*cli.Command{
 		CreateDeployment,
 		DeploymentList,
 		DeleteDeployment,
 		StatusDeployment,
+		LogsDeployment,
+		TopDeployment,
+		ScaleDeployment,
+		HistoryDeployment,
+		StateHistoryDeployment,
+		RollbackDeployment,
+		ExecDeployment,
+		TopUpDeployment,
+		OperationDeployment,
 	},
 }
 
+// operationPollInterval is how often a command waiting for an operation polls its state
+var operationPollInterval = 2 * time.Second
+
+var waitFlag = &cli.BoolFlag{
+	Name:  "wait",
+	Usage: "wait for the operation to finish",
+}
+
 var CreateDeployment = &cli.Command{
 	Name:  "create",
 	Usage: "create new deployment",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     "provider-id",
-			Usage:    "the provider id",
-			Required: true,
+			Name:  "provider-id",
+			Usage: "the provider id, selected by the manager if not set",
 		},
 		&cli.StringFlag{
 			Name:  "owner",
@@ -81,6 +119,45 @@ var CreateDeployment = &cli.Command{
 			Name:  "args",
 			Usage: "set the deployment running arguments",
 		},
+		&cli.StringSliceFlag{
+			Name:  "host",
+			Usage: "custom host routed to the port through the provider ingress",
+		},
+		&cli.IntFlag{
+			Name:  "replicas",
+			Usage: "the number of pods to run",
+			Value: 1,
+		},
+		&cli.StringSliceFlag{
+			Name:  "secret",
+			Usage: "secret exposed to the containers as an environment variable, format: NAME=VALUE",
+		},
+		&cli.StringSliceFlag{
+			Name:  "config-file",
+			Usage: "local file mounted into the containers, format: MOUNT_PATH=LOCAL_PATH",
+		},
+		&cli.StringFlag{
+			Name:  "registry-server",
+			Usage: "the private registry server the image is pulled from, e.g. registry.example.com",
+		},
+		&cli.StringFlag{
+			Name:  "registry-username",
+			Usage: "the private registry username",
+		},
+		&cli.StringFlag{
+			Name:    "registry-password",
+			Usage:   "the private registry password or access token",
+			EnvVars: []string{"TITAN_REGISTRY_PASSWORD"},
+		},
+		&cli.Float64Flag{
+			Name:  "balance",
+			Usage: "the initial balance charged for the running deployment, requires an admin token",
+		},
+		&cli.DurationFlag{
+			Name:  "duration",
+			Usage: "close the deployment after the duration, zero never expires, requires an admin token",
+		},
+		waitFlag,
 	},
 	Action: func(cctx *cli.Context) error {
 		api, closer, err := GetManagerAPI(cctx)
@@ -91,9 +168,34 @@ var CreateDeployment = &cli.Command{
 
 		ctx := ReqContext(cctx)
 		providerID := types.ProviderID(cctx.String("provider-id"))
+		credentials := registryCredentialsFromFlags(cctx)
 
 		if cctx.String("template") != "" {
-			return createDeploymentFromTemplate(ctx, api, providerID, cctx.String("template"))
+			deployment, err := deploymentFromTemplate(cctx.String("template"))
+			if err != nil {
+				return err
+			}
+
+			if providerID != "" {
+				deployment.ProviderID = providerID
+			}
+			if len(credentials) > 0 {
+				deployment.RegistryCredentials = credentials
+			}
+			if cctx.String("owner") != "" {
+				deployment.Owner = cctx.String("owner")
+			}
+
+			deployment.Signature, err = ownerSignature(cctx, types.OwnerOperationCreate, deployment.Owner, "", deployment)
+			if err != nil {
+				return err
+			}
+
+			operation, err := api.CreateDeployment(ctx, deployment)
+			if err != nil {
+				return err
+			}
+			return waitOperation(cctx, api, operation)
 		}
 
 		if cctx.String("image") == "" {
@@ -108,16 +210,23 @@ var CreateDeployment = &cli.Command{
 			}
 		}
 
+		secrets, configFiles, err := serviceConfigFromFlags(cctx)
+		if err != nil {
+			return err
+		}
+
 		deployment := &types.Deployment{
 			ProviderID: providerID,
 			Name:       cctx.String("name"),
+			Owner:      cctx.String("owner"),
 			Authority:  cctx.Bool("auth"),
 			Services: []*types.Service{
 				{
 					Image: cctx.String("image"),
 					Ports: []types.Port{
 						{
-							Port: cctx.Int("port"),
+							Port:  cctx.Int("port"),
+							Hosts: cctx.StringSlice("host"),
 						},
 					},
 					ComputeResources: types.ComputeResources{
@@ -125,30 +234,232 @@ var CreateDeployment = &cli.Command{
 						Memory:  cctx.Int64("mem"),
 						Storage: cctx.Int64("storage"),
 					},
-					Env:       env,
-					Arguments: cctx.StringSlice("args"),
+					Env:         env,
+					Arguments:   cctx.StringSlice("args"),
+					Replicas:    cctx.Int("replicas"),
+					Secrets:     secrets,
+					ConfigFiles: configFiles,
 				},
 			},
+			RegistryCredentials: credentials,
+			Balance:             cctx.Float64("balance"),
+		}
+
+		if cctx.Duration("duration") > 0 {
+			deployment.Expiration = time.Now().Add(cctx.Duration("duration"))
+		}
+
+		deployment.Signature, err = ownerSignature(cctx, types.OwnerOperationCreate, deployment.Owner, "", deployment)
+		if err != nil {
+			return err
+		}
+
+		operation, err := api.CreateDeployment(ctx, deployment)
+		if err != nil {
+			return err
+		}
+		return waitOperation(cctx, api, operation)
+	},
+}
+
+// waitOperation prints the operation and polls it until it finished if the wait flag is set.
+func waitOperation(cctx *cli.Context, managerApi api.Manager, operation *types.Operation) error {
+	fmt.Printf("DeploymentID:\t%s\n", operation.DeploymentID)
+	fmt.Printf("OperationID:\t%s\n", operation.ID)
+	if !cctx.Bool("wait") {
+		return nil
+	}
+
+	ctx := ReqContext(cctx)
+	ticker := time.NewTicker(operationPollInterval)
+	defer ticker.Stop()
+
+	for !operation.Done() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		var err error
+		operation, err = managerApi.GetOperation(ctx, operation.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("State:\t\t%s\n", types.OperationStateString(operation.State))
+	if operation.State == types.OperationStateFailed {
+		return errors.Errorf("%s deployment %s: %s", operation.Type, operation.DeploymentID, operation.Error)
+	}
+	return nil
+}
+
+var OperationDeployment = &cli.Command{
+	Name:      "operation",
+	Usage:     "show the state of a deployment operation",
+	ArgsUsage: "[operation id]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "watch",
+			Usage: "wait for the operation to finish",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		id := types.OperationID(cctx.Args().First())
+
+		var operation *types.Operation
+		if cctx.Bool("watch") {
+			operations, err := api.WatchOperation(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			for operation = range operations {
+				fmt.Printf("%s\t%s\n", operation.UpdatedAt.Format(defaultDateTimeLayout), types.OperationStateString(operation.State))
+			}
+		} else {
+			operation, err = api.GetOperation(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if operation == nil {
+			return errors.New("operation watch closed")
 		}
 
-		return api.CreateDeployment(ctx, deployment)
+		fmt.Printf("OperationID:\t%s\n", operation.ID)
+		fmt.Printf("DeploymentID:\t%s\n", operation.DeploymentID)
+		fmt.Printf("Type:\t\t%s\n", operation.Type)
+		fmt.Printf("State:\t\t%s\n", types.OperationStateString(operation.State))
+		if operation.Error != "" {
+			fmt.Printf("Error:\t\t%s\n", operation.Error)
+		}
+		fmt.Printf("CreatedTime:\t%s\n", operation.CreatedAt.Format(defaultDateTimeLayout))
+		fmt.Printf("UpdatedTime:\t%s\n", operation.UpdatedAt.Format(defaultDateTimeLayout))
+		return nil
 	},
 }
 
-func createDeploymentFromTemplate(ctx context.Context, api api.Manager, providerID types.ProviderID, path string) error {
+func serviceConfigFromFlags(cctx *cli.Context) ([]types.Secret, []types.ConfigFile, error) {
+	var secrets []types.Secret
+	for _, secret := range cctx.StringSlice("secret") {
+		parts := strings.SplitN(secret, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, errors.Errorf("invalid secret %s, format: NAME=VALUE", secret)
+		}
+		secrets = append(secrets, types.Secret{Name: parts[0], Value: parts[1], Env: parts[0]})
+	}
+
+	var configFiles []types.ConfigFile
+	for _, configFile := range cctx.StringSlice("config-file") {
+		parts := strings.SplitN(configFile, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, errors.Errorf("invalid config file %s, format: MOUNT_PATH=LOCAL_PATH", configFile)
+		}
+
+		content, err := os.ReadFile(parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		configFiles = append(configFiles, types.ConfigFile{Name: filepath.Base(parts[0]), Content: string(content), MountPath: parts[0]})
+	}
+
+	return secrets, configFiles, nil
+}
+
+func registryCredentialsFromFlags(cctx *cli.Context) []*types.RegistryCredential {
+	if cctx.String("registry-server") == "" {
+		return nil
+	}
+
+	return []*types.RegistryCredential{
+		{
+			Server:   cctx.String("registry-server"),
+			Username: cctx.String("registry-username"),
+			Password: cctx.String("registry-password"),
+		},
+	}
+}
+
+func deploymentFromTemplate(path string) (*types.Deployment, error) {
 	yamlFiles, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var deployment types.Deployment
 	err = yaml.Unmarshal(yamlFiles, &deployment)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	deployment.ProviderID = providerID
-	return api.CreateDeployment(ctx, &deployment)
+	return &deployment, nil
+}
+
+// ownerSignature signs the operation with the payload on the deployment with the owner key, it returns nil if no owner key is set.
+func ownerSignature(cctx *cli.Context, op types.OwnerOperation, owner string, id types.DeploymentID, payload interface{}) (*types.OwnerSignature, error) {
+	if cctx.String("owner-key") == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(cctx.String("owner-key"))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, errors.Errorf("decoding owner key: %v", err)
+	}
+
+	var privKey cryptotypes.PrivKey
+	keyType := types.KeyType(cctx.String("owner-key-type"))
+	switch keyType {
+	case types.KeyTypeSecp256k1:
+		privKey = &secp256k1.PrivKey{Key: key}
+	case types.KeyTypeEd25519:
+		privKey = &ed25519.PrivKey{Key: key}
+	default:
+		return nil, errors.Errorf("unsupported owner key type %q", keyType)
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+
+	nonce := hex.EncodeToString(random)
+	timestamp := time.Now().Unix()
+	message, err := types.OwnerSignMessage(op, owner, id, timestamp, nonce, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := privKey.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.OwnerSignature{
+		KeyType:   keyType,
+		PublicKey: privKey.PubKey().Bytes(),
+		Signature: signature,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+	}, nil
 }
 
 var DeploymentList = &cli.Command{
@@ -163,9 +474,13 @@ var DeploymentList = &cli.Command{
 			Name:  "id",
 			Usage: "the deployment id",
 		},
+		&cli.StringSliceFlag{
+			Name:  "state",
+			Usage: "only show the deployments in the states, one of: pending, deploying, running, degraded, failed, inactive, closing, deleted",
+		},
 		&cli.BoolFlag{
 			Name:  "show-all",
-			Usage: "show deleted and inactive deployments",
+			Usage: "show deployments in all the states",
 		},
 		&cli.IntFlag{
 			Name:  "page",
@@ -189,6 +504,7 @@ var DeploymentList = &cli.Command{
 
 		tw := tablewriter.New(
 			tablewriter.Col("ID"),
+			tablewriter.Col("Service"),
 			tablewriter.Col("Image"),
 			tablewriter.Col("State"),
 			tablewriter.Col("Authority"),
@@ -200,17 +516,29 @@ var DeploymentList = &cli.Command{
 			tablewriter.Col("Storage"),
 			tablewriter.Col("Provider"),
 			tablewriter.Col("Port"),
+			tablewriter.Col("Config"),
 			tablewriter.Col("CreatedTime"),
 		)
 
 		opts := &types.GetDeploymentOption{
 			Owner:        cctx.String("owner"),
-			State:        []types.DeploymentState{types.DeploymentStateActive},
+			State:        types.ActiveDeploymentStates,
 			DeploymentID: types.DeploymentID(cctx.String("id")),
 			Page:         cctx.Int("page"),
 			Size:         cctx.Int("size"),
 		}
 
+		if cctx.IsSet("state") {
+			opts.State = nil
+			for _, name := range cctx.StringSlice("state") {
+				state, err := types.ParseDeploymentState(name)
+				if err != nil {
+					return err
+				}
+				opts.State = append(opts.State, state)
+			}
+		}
+
 		if cctx.Bool("show-all") {
 			opts.State = types.AllDeploymentStates
 		}
@@ -222,20 +550,25 @@ var DeploymentList = &cli.Command{
 
 		for _, deployment := range deployments {
 			for _, service := range deployment.Services {
-				state := types.DeploymentStateInActive
-				if service.Status.TotalReplicas == service.Status.ReadyReplicas {
-					state = types.DeploymentStateActive
-				}
-
 				var exposePorts []string
 				for _, port := range service.Ports {
 					exposePorts = append(exposePorts, fmt.Sprintf("%d->%d", port.Port, port.ExposePort))
 				}
 
+				// only the names of the secrets and config files are listed, their values are redacted
+				var configs []string
+				for _, secret := range service.Secrets {
+					configs = append(configs, "secret:"+secret.Name)
+				}
+				for _, configFile := range service.ConfigFiles {
+					configs = append(configs, "file:"+configFile.Name)
+				}
+
 				m := map[string]interface{}{
 					"ID":          deployment.ID,
+					"Service":     service.Name,
 					"Image":       service.Image,
-					"State":       types.DeploymentStateString(state),
+					"State":       types.DeploymentStateString(deployment.State),
 					"Authority":   deployment.Authority,
 					"Total":       service.Status.TotalReplicas,
 					"Ready":       service.Status.ReadyReplicas,
@@ -245,6 +578,7 @@ var DeploymentList = &cli.Command{
 					"Storage":     units.BytesSize(float64(service.Storage * units.MiB)),
 					"Provider":    deployment.ProviderExposeIP,
 					"Port":        strings.Join(exposePorts, " "),
+					"Config":      strings.Join(configs, " "),
 					"CreatedTime": deployment.CreatedAt.Format(defaultDateTimeLayout),
 				}
 				tw.Write(m)
@@ -259,7 +593,9 @@ var DeploymentList = &cli.Command{
 var DeleteDeployment = &cli.Command{
 	Name:  "delete",
 	Usage: "delete deployment",
-	Flags: []cli.Flag{},
+	Flags: []cli.Flag{
+		waitFlag,
+	},
 	Action: func(cctx *cli.Context) error {
 		if cctx.NArg() != 1 {
 			return IncorrectNumArgs(cctx)
@@ -286,9 +622,19 @@ var DeleteDeployment = &cli.Command{
 		}
 
 		for _, deployment := range deployments {
-			err = api.CloseDeployment(ctx, deployment)
+			deployment.Signature, err = ownerSignature(cctx, types.OwnerOperationClose, deployment.Owner, deployment.ID, nil)
+			if err != nil {
+				return err
+			}
+
+			operation, err := api.CloseDeployment(ctx, deployment)
 			if err != nil {
 				log.Errorf("delete deployment failed: %v", err)
+				continue
+			}
+
+			if err := waitOperation(cctx, api, operation); err != nil {
+				log.Errorf("delete deployment failed: %v", err)
 			}
 		}
 
@@ -340,9 +686,35 @@ var StatusDeployment = &cli.Command{
 
 		fmt.Printf("DeploymentID:\t%s\n", deployment.ID)
 		fmt.Printf("State:\t\t%s\n", types.DeploymentStateString(deployment.State))
+		if deployment.StateReason != "" {
+			fmt.Printf("Reason:\t\t%s\n", deployment.StateReason)
+		}
+		fmt.Printf("Revision:\t%s\n", deployment.Version)
 		fmt.Printf("CreadTime:\t%v\n", deployment.CreatedAt)
+		fmt.Printf("Balance:\t%.4f\n", deployment.Balance)
+		fmt.Printf("Cost:\t\t%.4f/h\n", deployment.Cost)
+		if !deployment.Expiration.IsZero() {
+			fmt.Printf("Expiration:\t%s\n", deployment.Expiration.Format(defaultDateTimeLayout))
+		}
+		for _, service := range deployment.Services {
+			if len(service.URLs) > 0 {
+				fmt.Printf("URLs:\t\t[%s]\t%s\n", service.Name, strings.Join(service.URLs, " "))
+			}
+		}
+		for _, service := range deployment.Services {
+			health := service.Status.Health
+			if health == "" {
+				health = "Unknown"
+			}
+			fmt.Printf("Health:\t\t[%s]\t%s\t%d/%d ready\n", service.Name, health, service.Status.ReadyReplicas, service.Status.TotalReplicas)
+		}
 		fmt.Printf("--------\nEvents:\n")
 
+		deployment.Signature, err = ownerSignature(cctx, types.OwnerOperationEvents, deployment.Owner, deployment.ID, nil)
+		if err != nil {
+			return err
+		}
+
 		serviceEvents, err := api.GetEvents(ctx, deployment)
 		if err != nil {
 			return err
@@ -350,13 +722,22 @@ var StatusDeployment = &cli.Command{
 
 		for _, sv := range serviceEvents {
 			for i, event := range sv.Events {
-				fmt.Printf("%d.\t[%s]\t%s\n", i, sv.ServiceName, event)
+				reason := event.Reason
+				if event.Probe != "" {
+					reason = fmt.Sprintf("%s(%s probe)", event.Reason, event.Probe)
+				}
+				fmt.Printf("%d.\t[%s]\t%s\t%s\t%s\t%s\n", i, sv.ServiceName, event.Time.Format(defaultDateTimeLayout), event.Type, reason, event.Message)
 			}
 		}
 
 		if cctx.Bool("log") {
 			fmt.Printf("--------\nLogs:\n")
 
+			deployment.Signature, err = ownerSignature(cctx, types.OwnerOperationLogs, deployment.Owner, deployment.ID, nil)
+			if err != nil {
+				return err
+			}
+
 			serviceLogs, err := api.GetLogs(ctx, deployment)
 			if err != nil {
 				return err
@@ -372,3 +753,499 @@ var StatusDeployment = &cli.Command{
 		return nil
 	},
 }
+
+var TopDeployment = &cli.Command{
+	Name:      "top",
+	Usage:     "show the cpu and memory usage of the deployment pods next to their requests and limits",
+	ArgsUsage: "[deployment id]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		deployments, err := api.GetDeploymentList(ctx, &types.GetDeploymentOption{
+			DeploymentID: deploymentID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(deployments) == 0 {
+			return errors.New("deployment not found")
+		}
+
+		deployments[0].Signature, err = ownerSignature(cctx, types.OwnerOperationMetrics, deployments[0].Owner, deployments[0].ID, nil)
+		if err != nil {
+			return err
+		}
+
+		metrics, err := api.GetDeploymentMetrics(ctx, deployments[0])
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Service"),
+			tablewriter.Col("Pod"),
+			tablewriter.Col("CPU"),
+			tablewriter.Col("CPURequest"),
+			tablewriter.Col("CPULimit"),
+			tablewriter.Col("Memory"),
+			tablewriter.Col("MemoryRequest"),
+			tablewriter.Col("MemoryLimit"),
+		)
+
+		for _, service := range metrics.Services {
+			row := func(pod string, usage types.ResourceUsage) map[string]interface{} {
+				return map[string]interface{}{
+					"Service":       service.ServiceName,
+					"Pod":           pod,
+					"CPU":           fmt.Sprintf("%.3f", usage.CPU),
+					"CPURequest":    fmt.Sprintf("%.3f", service.Requests.CPU),
+					"CPULimit":      fmt.Sprintf("%.3f", service.Limits.CPU),
+					"Memory":        units.BytesSize(float64(usage.Memory * units.MiB)),
+					"MemoryRequest": units.BytesSize(float64(service.Requests.Memory * units.MiB)),
+					"MemoryLimit":   units.BytesSize(float64(service.Limits.Memory * units.MiB)),
+				}
+			}
+
+			if len(service.Pods) == 0 {
+				tw.Write(row("-", service.Usage))
+				continue
+			}
+
+			for _, pod := range service.Pods {
+				tw.Write(row(pod.PodName, pod.Usage))
+			}
+		}
+
+		tw.Flush(os.Stdout)
+		return nil
+	},
+}
+
+var ScaleDeployment = &cli.Command{
+	Name:      "scale",
+	Usage:     "set the number of replicas of a deployment service",
+	ArgsUsage: "[deployment id] [service name] [replicas]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 3 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		replicas, err := strconv.Atoi(cctx.Args().Get(2))
+		if err != nil {
+			return errors.Errorf("parsing replicas: %v", err)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		return api.ScaleDeployment(ctx, deploymentID, cctx.Args().Get(1), replicas)
+	},
+}
+
+var HistoryDeployment = &cli.Command{
+	Name:      "history",
+	Usage:     "show the revisions of a deployment",
+	ArgsUsage: "[deployment id]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		revisions, err := api.GetDeploymentRevisions(ctx, deploymentID)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Revision"),
+			tablewriter.Col("Service"),
+			tablewriter.Col("Image"),
+			tablewriter.Col("Replicas"),
+			tablewriter.Col("CreatedTime"),
+		)
+
+		for _, revision := range revisions {
+			for _, service := range revision.Spec.Services {
+				m := map[string]interface{}{
+					"Revision":    revision.Revision,
+					"Service":     service.Name,
+					"Image":       service.Image,
+					"Replicas":    service.Replicas,
+					"CreatedTime": revision.CreatedAt.Format(defaultDateTimeLayout),
+				}
+				tw.Write(m)
+			}
+		}
+
+		tw.Flush(os.Stdout)
+		return nil
+	},
+}
+
+var StateHistoryDeployment = &cli.Command{
+	Name:      "states",
+	Usage:     "show the state transitions of a deployment",
+	ArgsUsage: "[deployment id]",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "page",
+			Usage: "the page number",
+			Value: 1,
+		},
+		&cli.IntFlag{
+			Name:  "size",
+			Usage: "the page size",
+			Value: 10,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		changes, err := api.GetDeploymentStateChanges(ctx, &types.GetDeploymentOption{
+			DeploymentID: types.DeploymentID(cctx.Args().First()),
+			Page:         cctx.Int("page"),
+			Size:         cctx.Int("size"),
+		})
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("State"),
+			tablewriter.Col("Reason"),
+			tablewriter.Col("Time"),
+		)
+
+		for _, change := range changes {
+			m := map[string]interface{}{
+				"State":  types.DeploymentStateString(change.State),
+				"Reason": change.Reason,
+				"Time":   change.CreatedAt.Format(defaultDateTimeLayout),
+			}
+			tw.Write(m)
+		}
+
+		tw.Flush(os.Stdout)
+		return nil
+	},
+}
+
+var RollbackDeployment = &cli.Command{
+	Name:      "rollback",
+	Usage:     "roll a deployment back to a previous revision",
+	ArgsUsage: "[deployment id] [revision]",
+	Flags: []cli.Flag{
+		waitFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		revision, err := strconv.Atoi(cctx.Args().Get(1))
+		if err != nil {
+			return errors.Errorf("parsing revision: %v", err)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		operation, err := api.RollbackDeployment(ctx, deploymentID, revision)
+		if err != nil {
+			return err
+		}
+		return waitOperation(cctx, api, operation)
+	},
+}
+
+var TopUpDeployment = &cli.Command{
+	Name:      "topup",
+	Usage:     "add balance to a deployment and extend its expiration",
+	ArgsUsage: "[deployment id]",
+	Flags: []cli.Flag{
+		&cli.Float64Flag{
+			Name:  "amount",
+			Usage: "the amount added to the deployment balance",
+		},
+		&cli.DurationFlag{
+			Name:  "duration",
+			Usage: "extend the expiration of the deployment by the duration",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		return api.TopUpDeployment(ctx, deploymentID, cctx.Float64("amount"), cctx.Duration("duration"))
+	},
+}
+
+var LogsDeployment = &cli.Command{
+	Name:      "logs",
+	Usage:     "show deployment logs",
+	ArgsUsage: "[deployment id]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "follow",
+			Aliases: []string{"f"},
+			Usage:   "follow the log output",
+		},
+		&cli.Int64Flag{
+			Name:  "tail",
+			Usage: "number of lines to show from the end of the logs, all logs if not set",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "show logs since the relative duration, e.g. 10m",
+		},
+		&cli.StringFlag{
+			Name:  "service",
+			Usage: "only show the logs of the service",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(cctx.Args().First())
+
+		deployments, err := api.GetDeploymentList(ctx, &types.GetDeploymentOption{
+			DeploymentID: deploymentID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(deployments) == 0 {
+			return errors.New("deployment not found")
+		}
+
+		opt := &types.LogOption{
+			ServiceName: cctx.String("service"),
+			TailLines:   cctx.Int64("tail"),
+			Follow:      cctx.Bool("follow"),
+		}
+
+		if cctx.IsSet("since") {
+			opt.SinceTime = time.Now().Add(-cctx.Duration("since"))
+		}
+
+		deployments[0].Signature, err = ownerSignature(cctx, types.OwnerOperationLogs, deployments[0].Owner, deployments[0].ID, opt)
+		if err != nil {
+			return err
+		}
+
+		logCh, err := api.GetLogStream(ctx, deployments[0], opt)
+		if err != nil {
+			return err
+		}
+
+		for line := range logCh {
+			if opt.ServiceName != "" {
+				fmt.Println(line.Line)
+				continue
+			}
+			fmt.Printf("[%s]\t%s\n", line.ServiceName, line.Line)
+		}
+
+		return nil
+	},
+}
+
+var ExecDeployment = &cli.Command{
+	Name:      "exec",
+	Usage:     "execute a command in a deployment container",
+	ArgsUsage: "[deployment id] [service name] -- [command...]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "stdin",
+			Aliases: []string{"i"},
+			Usage:   "pass stdin to the container",
+		},
+		&cli.BoolFlag{
+			Name:    "tty",
+			Aliases: []string{"t"},
+			Usage:   "stdin is a TTY",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		args, command := splitCommandArgs(cctx.Args().Slice())
+		if len(args) < 1 || len(args) > 2 || len(command) == 0 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+		deploymentID := types.DeploymentID(args[0])
+
+		deployments, err := api.GetDeploymentList(ctx, &types.GetDeploymentOption{
+			DeploymentID: deploymentID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(deployments) == 0 {
+			return errors.New("deployment not found")
+		}
+		deployment := deployments[0]
+
+		opt := &types.ExecOption{
+			SessionID: uuid.NewString(),
+			Command:   command,
+			Stdin:     cctx.Bool("stdin"),
+			TTY:       cctx.Bool("tty"),
+		}
+
+		if len(args) > 1 {
+			opt.ServiceName = args[1]
+		}
+
+		stdinFd := int(os.Stdin.Fd())
+		if opt.TTY {
+			if !term.IsTerminal(stdinFd) {
+				return errors.New("the input device is not a TTY")
+			}
+
+			width, height, err := term.GetSize(stdinFd)
+			if err == nil {
+				opt.Size = &types.TerminalSize{Width: uint16(width), Height: uint16(height)}
+			}
+		}
+
+		outputCh, err := api.ExecDeployment(ctx, deployment, opt)
+		if err != nil {
+			return err
+		}
+
+		if opt.TTY {
+			state, err := term.MakeRaw(stdinFd)
+			if err != nil {
+				return err
+			}
+			defer term.Restore(stdinFd, state) //nolint:errcheck
+
+			go watchTerminalResize(ctx, stdinFd, func(size *types.TerminalSize) {
+				if err := api.ExecInput(ctx, deployment, &types.ExecInput{SessionID: opt.SessionID, Resize: size}); err != nil {
+					log.Warnf("resize terminal: %v", err)
+				}
+			})
+		}
+
+		if opt.Stdin {
+			go func() {
+				buf := make([]byte, 32*1024)
+				for {
+					n, err := os.Stdin.Read(buf)
+					if n > 0 {
+						input := &types.ExecInput{SessionID: opt.SessionID, Data: buf[:n]}
+						if err := api.ExecInput(ctx, deployment, input); err != nil {
+							return
+						}
+					}
+					if err != nil {
+						api.ExecInput(ctx, deployment, &types.ExecInput{SessionID: opt.SessionID, CloseStdin: true}) //nolint:errcheck
+						return
+					}
+				}
+			}()
+		}
+
+		for output := range outputCh {
+			if len(output.Stdout) > 0 {
+				os.Stdout.Write(output.Stdout) //nolint:errcheck
+			}
+			if len(output.Stderr) > 0 {
+				os.Stderr.Write(output.Stderr) //nolint:errcheck
+			}
+			if !output.Exited {
+				continue
+			}
+			if output.Error != "" {
+				return errors.New(output.Error)
+			}
+			if output.ExitCode != 0 {
+				return cli.Exit("", output.ExitCode)
+			}
+			return nil
+		}
+
+		return errors.New("exec session closed unexpectedly")
+	},
+}
+
+// splitCommandArgs splits the arguments at "--", returns the arguments before and the command after it.
+func splitCommandArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
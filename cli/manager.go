@@ -8,4 +8,6 @@ import (
 var ManagerCMDs = []*cli.Command{
 	WithCategory("provider", providerCmds),
 	WithCategory("deployment", deploymentCmds),
+	WithCategory("quota", quotaCmds),
+	WithCategory("usage", usageCmds),
 }
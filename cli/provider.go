@@ -14,6 +14,7 @@ var providerCmds = &cli.Command{
 	Usage: "Manage provider",
 	Subcommands: []*cli.Command{
 		ProviderList,
+		ProviderStateHistory,
 	},
 }
 
@@ -57,6 +58,7 @@ var ProviderList = &cli.Command{
 			tablewriter.Col("CPUAvail"),
 			tablewriter.Col("MemoryAvail"),
 			tablewriter.Col("StorageAvail"),
+			tablewriter.Col("LastSeen"),
 			tablewriter.Col("CreatedTime"),
 		)
 
@@ -74,20 +76,81 @@ var ProviderList = &cli.Command{
 		}
 
 		for _, provider := range providers {
-			resource, err := api.GetStatistics(ctx, provider.ID)
-			if err != nil {
-				continue
-			}
-
 			m := map[string]interface{}{
 				"ID":           provider.ID,
 				"IP":           provider.IP,
 				"State":        types.ProviderStateString(provider.State),
 				"HostURI":      provider.HostURI,
-				"CPUAvail":     fmt.Sprintf("%.1f/%.1f", resource.CPUCores.Available, resource.CPUCores.MaxCPUCores),
-				"MemoryAvail":  fmt.Sprintf("%s/%s", units.BytesSize(float64(resource.Memory.Available)), units.BytesSize(float64(resource.Memory.MaxMemory))),
-				"StorageAvail": fmt.Sprintf("%s/%s", units.BytesSize(float64(resource.Storage.Available)), units.BytesSize(float64(resource.Storage.MaxStorage))),
-				"CreateTime":   provider.CreatedAt.Format(defaultDateTimeLayout),
+				"CPUAvail":     "-",
+				"MemoryAvail":  "-",
+				"StorageAvail": "-",
+				"LastSeen":     provider.LastSeen.Format(defaultDateTimeLayout),
+				"CreatedTime":  provider.CreatedAt.Format(defaultDateTimeLayout),
+			}
+
+			if provider.State == types.ProviderStateOnline {
+				resource, err := api.GetStatistics(ctx, provider.ID)
+				if err == nil {
+					m["CPUAvail"] = fmt.Sprintf("%.1f/%.1f", resource.CPUCores.Available, resource.CPUCores.MaxCPUCores)
+					m["MemoryAvail"] = fmt.Sprintf("%s/%s", units.BytesSize(float64(resource.Memory.Available)), units.BytesSize(float64(resource.Memory.MaxMemory)))
+					m["StorageAvail"] = fmt.Sprintf("%s/%s", units.BytesSize(float64(resource.Storage.Available)), units.BytesSize(float64(resource.Storage.MaxStorage)))
+				}
+			}
+			tw.Write(m)
+		}
+
+		tw.Flush(os.Stdout)
+		return nil
+	},
+}
+
+var ProviderStateHistory = &cli.Command{
+	Name:      "history",
+	Usage:     "Show provider state transitions",
+	ArgsUsage: "[provider id]",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "page",
+			Usage: "the page number",
+			Value: 1,
+		},
+		&cli.IntFlag{
+			Name:  "size",
+			Usage: "the page size",
+			Value: 10,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		tw := tablewriter.New(
+			tablewriter.Col("ProviderID"),
+			tablewriter.Col("State"),
+			tablewriter.Col("Reason"),
+			tablewriter.Col("Time"),
+		)
+
+		changes, err := api.GetProviderStateChanges(ctx, &types.GetProviderOption{
+			ID:   types.ProviderID(cctx.Args().First()),
+			Page: cctx.Int("page"),
+			Size: cctx.Int("size"),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			m := map[string]interface{}{
+				"ProviderID": change.ProviderID,
+				"State":      types.ProviderStateString(change.State),
+				"Reason":     change.Reason,
+				"Time":       change.CreatedAt.Format(defaultDateTimeLayout),
 			}
 			tw.Write(m)
 		}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/urfave/cli/v2"
+)
+
+var quotaCmds = &cli.Command{
+	Name:  "quota",
+	Usage: "Manage owner quotas",
+	Subcommands: []*cli.Command{
+		QuotaSet,
+		QuotaShow,
+	},
+}
+
+var QuotaSet = &cli.Command{
+	Name:      "set",
+	Usage:     "set the quota of an owner, a zero limit is unlimited",
+	ArgsUsage: "[owner]",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "deployments",
+			Usage: "the max number of deployments",
+		},
+		&cli.Float64Flag{
+			Name:  "cpu",
+			Usage: "the max cpu cores of all deployments",
+		},
+		&cli.Int64Flag{
+			Name:  "mem",
+			Usage: "the max memory of all deployments in MB",
+		},
+		&cli.Int64Flag{
+			Name:  "storage",
+			Usage: "the max storage of all deployments in MB",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		return api.SetQuota(ctx, &types.Quota{
+			Owner:          cctx.Args().First(),
+			MaxDeployments: cctx.Int("deployments"),
+			MaxCPU:         cctx.Float64("cpu"),
+			MaxMemory:      cctx.Int64("mem"),
+			MaxStorage:     cctx.Int64("storage"),
+		})
+	},
+}
+
+var QuotaShow = &cli.Command{
+	Name:      "show",
+	Usage:     "show the quota of an owner and its usage",
+	ArgsUsage: "[owner]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		usage, err := api.GetQuota(ctx, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Owner:\t\t%s\n", usage.Quota.Owner)
+		fmt.Printf("Deployments:\t%d/%s\n", usage.Deployments, quotaLimit(float64(usage.Quota.MaxDeployments)))
+		fmt.Printf("CPU:\t\t%v/%s\n", usage.Used.CPU, quotaLimit(usage.Quota.MaxCPU))
+		fmt.Printf("Memory:\t\t%dMB/%s\n", usage.Used.Memory, quotaLimit(float64(usage.Quota.MaxMemory)))
+		fmt.Printf("Storage:\t%dMB/%s\n", usage.Used.Storage, quotaLimit(float64(usage.Quota.MaxStorage)))
+		return nil
+	},
+}
+
+func quotaLimit(limit float64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%v", limit)
+}
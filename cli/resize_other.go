@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package cli
+
+import (
+	"context"
+
+	"github.com/gnasnik/titan-container/api/types"
+)
+
+// watchTerminalResize is a no-op, window change signals are not supported on this platform.
+func watchTerminalResize(ctx context.Context, fd int, resize func(size *types.TerminalSize)) {
+}
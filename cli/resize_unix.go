@@ -0,0 +1,34 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"golang.org/x/term"
+)
+
+// watchTerminalResize calls resize with the new terminal size whenever the window changes.
+func watchTerminalResize(ctx context.Context, fd int, resize func(size *types.TerminalSize)) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-sigCh:
+			width, height, err := term.GetSize(fd)
+			if err != nil {
+				continue
+			}
+			resize(&types.TerminalSize{Width: uint16(width), Height: uint16(height)})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
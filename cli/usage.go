@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var usageDateLayout = "2006-01-02"
+
+var usageCmds = &cli.Command{
+	Name:  "usage",
+	Usage: "Manage deployment usage records",
+	Subcommands: []*cli.Command{
+		UsageExport,
+	},
+}
+
+var UsageExport = &cli.Command{
+	Name:      "export",
+	Usage:     "export the daily usage of the deployments of an owner as csv, the usage of all owners if no owner is given",
+	ArgsUsage: "[owner]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "the first day of the period in UTC, formatted as 2006-01-02, default the first day of the month",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "the last day of the period in UTC, formatted as 2006-01-02, default today",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "the csv file to write, default stdout",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() > 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		var err error
+		if cctx.IsSet("from") {
+			from, err = time.Parse(usageDateLayout, cctx.String("from"))
+			if err != nil {
+				return errors.Errorf("parsing from: %v", err)
+			}
+		}
+		if cctx.IsSet("to") {
+			to, err = time.Parse(usageDateLayout, cctx.String("to"))
+			if err != nil {
+				return errors.Errorf("parsing to: %v", err)
+			}
+		}
+
+		api, closer, err := GetManagerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		// the last day is included
+		usage, err := api.GetUsage(ctx, cctx.Args().First(), from, to.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if cctx.IsSet("output") {
+			file, err := os.Create(cctx.String("output"))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		w := csv.NewWriter(out)
+		err = w.Write([]string{"day", "owner", "deployment_id", "provider_id", "cpu_seconds", "memory_byte_seconds",
+			"storage_byte_seconds", "used_cpu_seconds", "used_memory_byte_seconds"})
+		if err != nil {
+			return err
+		}
+
+		formatFloat := func(f float64) string {
+			return strconv.FormatFloat(f, 'f', 3, 64)
+		}
+
+		for _, u := range usage {
+			err = w.Write([]string{u.Day, u.Owner, string(u.DeploymentID), string(u.ProviderID), formatFloat(u.CPUSeconds),
+				formatFloat(u.MemoryByteSeconds), formatFloat(u.StorageByteSeconds), formatFloat(u.UsedCPUSeconds), formatFloat(u.UsedMemoryByteSeconds)})
+			if err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+		return w.Error()
+	},
+}
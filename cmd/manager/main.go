@@ -9,12 +9,14 @@ import (
 	lcli "github.com/gnasnik/titan-container/cli"
 	cliutil "github.com/gnasnik/titan-container/cli/util"
 	liblog "github.com/gnasnik/titan-container/lib/log"
+	"github.com/gnasnik/titan-container/metrics"
 	"github.com/gnasnik/titan-container/node"
 	"github.com/gnasnik/titan-container/node/config"
 	"github.com/gnasnik/titan-container/node/repo"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
+	"go.opencensus.io/stats/view"
 	"golang.org/x/xerrors"
 	"os"
 )
@@ -160,6 +162,13 @@ var runCmd = &cli.Command{
 			return err
 		}
 
+		// Register all metric views
+		if err := view.Register(
+			metrics.ManagerNodeViews...,
+		); err != nil {
+			log.Fatalf("Cannot register the view: %v", err)
+		}
+
 		shutdownChan := make(chan struct{})
 
 		var managerAPI api.Manager
@@ -164,7 +164,7 @@ var runCmd = &cli.Command{
 
 		// Register all metric views
 		if err := view.Register(
-			metrics.DefaultViews...,
+			metrics.ProviderNodeViews...,
 		); err != nil {
 			log.Fatalf("Cannot register the view: %v", err)
 		}
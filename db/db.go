@@ -35,7 +35,7 @@ func SqlDB(dsn string) (*sqlx.DB, error) {
 var createMainDBSQL embed.FS
 
 func createAllTables(ctx context.Context, mainDB *sqlx.DB) error {
-	fileNames := []string{"providers", "deployments", "services", "properties"}
+	fileNames := []string{"providers", "deployments", "services", "properties", "provider_state_changes", "deployment_revisions", "quotas", "deployment_state_changes", "operations", "usage_records"}
 
 	for _, fileName := range fileNames {
 		content, _ := createMainDBSQL.ReadFile("sql/" + fileName + ".sql")
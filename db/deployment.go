@@ -7,6 +7,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (m *ManagerDB) CreateDeployment(ctx context.Context, deployment *types.Deployment) error {
@@ -16,11 +17,17 @@ func (m *ManagerDB) CreateDeployment(ctx context.Context, deployment *types.Depl
 	}
 	defer tx.Rollback()
 
+	deployment.StateReason = truncateReason(deployment.StateReason)
 	err = addNewDeployment(ctx, tx, deployment)
 	if err != nil {
 		return err
 	}
 
+	err = addDeploymentStateChange(ctx, tx, stateChangeOf(deployment))
+	if err != nil {
+		return err
+	}
+
 	err = addNewServices(ctx, tx, deployment.Services)
 	if err != nil {
 		return err
@@ -29,18 +36,78 @@ func (m *ManagerDB) CreateDeployment(ctx context.Context, deployment *types.Depl
 	return tx.Commit()
 }
 
+func (m *ManagerDB) UpdateDeployment(ctx context.Context, deployment *types.Deployment) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deployment.StateReason = truncateReason(deployment.StateReason)
+	err = addNewDeployment(ctx, tx, deployment)
+	if err != nil {
+		return err
+	}
+
+	err = addDeploymentStateChange(ctx, tx, stateChangeOf(deployment))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM services where deployment_id = ?`, deployment.ID)
+	if err != nil {
+		return err
+	}
+
+	err = addNewServices(ctx, tx, deployment.Services)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addNewDeployment inserts the deployment or updates its spec, the balance and the expiration of an existing deployment
+// only change through ChargeDeployment and TopUpDeployment.
 func addNewDeployment(ctx context.Context, tx *sqlx.Tx, deployment *types.Deployment) error {
-	qry := `INSERT INTO deployments (id, name, owner, state, type, authority, version, balance, cost, expiration, provider_id, created_at, updated_at) 
-		        VALUES (:id, :name, :owner, :state, :type, :authority, :version, :balance, :cost, :expiration, :provider_id, :created_at, :updated_at)
-		         ON DUPLICATE KEY UPDATE  state=:state, authority=:authority, version=:version, balance=:balance, cost=:cost, expiration=:expiration, updated_at=:updated_at`
+	qry := `INSERT INTO deployments (id, name, owner, state, state_reason, type, authority, version, balance, cost, expiration, provider_id, registry_credentials, created_at, updated_at) 
+		        VALUES (:id, :name, :owner, :state, :state_reason, :type, :authority, :version, :balance, :cost, :expiration, :provider_id, :registry_credentials, :created_at, :updated_at)
+		         ON DUPLICATE KEY UPDATE  state=:state, state_reason=:state_reason, authority=:authority, version=:version, cost=:cost, registry_credentials=:registry_credentials, updated_at=:updated_at`
 	_, err := tx.NamedExecContext(ctx, qry, deployment)
 
 	return err
 }
 
+// maxStateReasonLength is the size of the state reason columns
+const maxStateReasonLength = 256
+
+func truncateReason(reason string) string {
+	if len(reason) > maxStateReasonLength {
+		return reason[:maxStateReasonLength]
+	}
+	return reason
+}
+
+func stateChangeOf(deployment *types.Deployment) *types.DeploymentStateChange {
+	return &types.DeploymentStateChange{
+		DeploymentID: deployment.ID,
+		State:        deployment.State,
+		Reason:       deployment.StateReason,
+		CreatedAt:    deployment.UpdatedAt,
+	}
+}
+
+func addDeploymentStateChange(ctx context.Context, tx *sqlx.Tx, change *types.DeploymentStateChange) error {
+	qry := `INSERT INTO deployment_state_changes (deployment_id, state, reason, created_at) 
+		        VALUES (:deployment_id, :state, :reason, :created_at)`
+	_, err := tx.NamedExecContext(ctx, qry, change)
+
+	return err
+}
+
 func addNewServices(ctx context.Context, tx *sqlx.Tx, services []*types.Service) error {
-	qry := `INSERT INTO services (id, name, image, ports, cpu, memory, storage, deployment_id, env, arguments, error_message, created_at, updated_at) 
-		        VALUES (:id,:name, :image, :ports, :cpu, :memory, :storage, :deployment_id, :env, :arguments, :error_message, :created_at, :updated_at)`
+	qry := `INSERT INTO services (id, name, image, ports, cpu, memory, storage, replicas, volumes, probes, deployment_id, env, arguments, encrypted_config, error_message, created_at, updated_at) 
+		        VALUES (:id,:name, :image, :ports, :cpu, :memory, :storage, :replicas, :volumes, :probes, :deployment_id, :env, :arguments, :encrypted_config, :error_message, :created_at, :updated_at)`
 	_, err := tx.NamedExecContext(ctx, qry, services)
 
 	return err
@@ -51,20 +118,26 @@ type DeploymentService struct {
 	types.Service `db:"service"`
 }
 
-func (m *ManagerDB) GetDeployments(ctx context.Context, option *types.GetDeploymentOption) ([]*types.Deployment, error) {
-	var ds []*DeploymentService
-	qry := `SELECT d.*, s.image as 'service.image', 
+const selectDeploymentServices = `SELECT d.*, s.image as 'service.image', 
 			s.name as 'service.name',
 			s.cpu as 'service.cpu', 
 			s.memory as 'service.memory',
 			s.storage as 'service.storage', 
+			s.replicas as 'service.replicas', 
+			s.volumes as 'service.volumes', 
+			s.probes as 'service.probes', 
 			s.ports as 'service.ports', 
 			s.env as 'service.env', 
 			s.arguments as 'service.arguments', 
+			s.encrypted_config as 'service.encrypted_config', 
 			s.error_message  as 'service.error_message',
 			p.host_uri  as 'provider_expose_ip'
 		FROM deployments d LEFT JOIN services s ON d.id = s.deployment_id LEFT JOIN providers p ON d.provider_id = p.id`
 
+func (m *ManagerDB) GetDeployments(ctx context.Context, option *types.GetDeploymentOption) ([]*types.Deployment, error) {
+	var ds []*DeploymentService
+	qry := selectDeploymentServices
+
 	var condition []string
 	if option.DeploymentID != "" {
 		condition = append(condition, fmt.Sprintf(`d.id = '%s'`, option.DeploymentID))
@@ -104,6 +177,27 @@ func (m *ManagerDB) GetDeployments(ctx context.Context, option *types.GetDeploym
 		return nil, err
 	}
 
+	return groupDeploymentServices(ds), nil
+}
+
+// GetDeploymentsByState returns all the deployments in the states with their services.
+func (m *ManagerDB) GetDeploymentsByState(ctx context.Context, states []types.DeploymentState) ([]*types.Deployment, error) {
+	var ss []string
+	for _, s := range states {
+		ss = append(ss, strconv.Itoa(int(s)))
+	}
+
+	var ds []*DeploymentService
+	qry := selectDeploymentServices + fmt.Sprintf(` WHERE d.state in (%s)`, strings.Join(ss, ","))
+	err := m.db.SelectContext(ctx, &ds, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupDeploymentServices(ds), nil
+}
+
+func groupDeploymentServices(ds []*DeploymentService) []*types.Deployment {
 	var out []*types.Deployment
 	deploymentToServices := make(map[types.DeploymentID]*types.Deployment)
 	for _, d := range ds {
@@ -116,15 +210,266 @@ func (m *ManagerDB) GetDeployments(ctx context.Context, option *types.GetDeploym
 		deploymentToServices[d.Deployment.ID].Services = append(deploymentToServices[d.Deployment.ID].Services, &d.Service)
 	}
 
-	return out, nil
+	return out
 }
 
-func (m *ManagerDB) UpdateDeploymentState(ctx context.Context, id types.DeploymentID, state types.DeploymentState) error {
-	qry := `Update deployments set state = ? where id = ?`
-	_, err := m.db.ExecContext(ctx, qry, state, id)
+// UpdateDeploymentState moves the deployment to the state and records the transition.
+func (m *ManagerDB) UpdateDeploymentState(ctx context.Context, id types.DeploymentID, state types.DeploymentState, reason string) error {
+	_, err := m.updateDeploymentState(ctx, id, nil, state, reason)
 	return err
 }
 
+// UpdateDeploymentStateFrom moves the deployment to the state and records the transition only if the deployment
+// is still in the from state, it reports whether the deployment moved.
+func (m *ManagerDB) UpdateDeploymentStateFrom(ctx context.Context, id types.DeploymentID, from, state types.DeploymentState, reason string) (bool, error) {
+	return m.updateDeploymentState(ctx, id, &from, state, reason)
+}
+
+func (m *ManagerDB) updateDeploymentState(ctx context.Context, id types.DeploymentID, from *types.DeploymentState, state types.DeploymentState, reason string) (bool, error) {
+	reason = truncateReason(reason)
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	qry := `Update deployments set state = ?, state_reason = ?, updated_at = ? where id = ?`
+	args := []interface{}{state, reason, now, id}
+	if from != nil {
+		qry += ` and state = ?`
+		args = append(args, *from)
+	}
+
+	result, err := tx.ExecContext(ctx, qry, args...)
+	if err != nil {
+		return false, err
+	}
+
+	if from != nil {
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		if rows == 0 {
+			return false, nil
+		}
+	}
+
+	err = addDeploymentStateChange(ctx, tx, &types.DeploymentStateChange{
+		DeploymentID: id,
+		State:        state,
+		Reason:       reason,
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ChargeDeployment records the hourly cost of the deployment, subtracts the amount from its balance and returns the balance left.
+func (m *ManagerDB) ChargeDeployment(ctx context.Context, id types.DeploymentID, cost, amount float64) (float64, error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qry := `Update deployments set cost = ?, balance = balance - ? where id = ?`
+	_, err = tx.ExecContext(ctx, qry, cost, amount, id)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance float64
+	err = tx.GetContext(ctx, &balance, `SELECT balance FROM deployments WHERE id = ?`, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, tx.Commit()
+}
+
+// TopUpDeployment adds the amount to the balance of the deployment and sets its expiration.
+func (m *ManagerDB) TopUpDeployment(ctx context.Context, id types.DeploymentID, amount float64, expiration time.Time) error {
+	qry := `Update deployments set balance = balance + ?, expiration = ?, updated_at = ? where id = ?`
+	_, err := m.db.ExecContext(ctx, qry, amount, expiration, time.Now(), id)
+	return err
+}
+
+func (m *ManagerDB) UpdateServiceReplicas(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error {
+	qry := `Update services set replicas = ?, updated_at = ? where deployment_id = ? and name = ?`
+	_, err := m.db.ExecContext(ctx, qry, replicas, time.Now(), id, serviceName)
+	return err
+}
+
+// UpdateDeploymentStateByProvider moves the deployments of the provider in one of the from states to the state
+// and records their transitions.
+func (m *ManagerDB) UpdateDeploymentStateByProvider(ctx context.Context, providerID types.ProviderID, from []types.DeploymentState, to types.DeploymentState, reason string) error {
+	var ss []string
+	for _, s := range from {
+		ss = append(ss, strconv.Itoa(int(s)))
+	}
+	condition := fmt.Sprintf(`provider_id = ? and state in (%s)`, strings.Join(ss, ","))
+	reason = truncateReason(reason)
+
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	qry := `INSERT INTO deployment_state_changes (deployment_id, state, reason, created_at) 
+		        SELECT id, ?, ?, ? FROM deployments WHERE ` + condition
+	_, err = tx.ExecContext(ctx, qry, to, reason, now, providerID)
+	if err != nil {
+		return err
+	}
+
+	qry = `Update deployments set state = ?, state_reason = ?, updated_at = ? where ` + condition
+	_, err = tx.ExecContext(ctx, qry, to, reason, now, providerID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDeploymentStateChanges returns the state transitions of the deployments, the latest first.
+func (m *ManagerDB) GetDeploymentStateChanges(ctx context.Context, option *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error) {
+	qry := `SELECT * from deployment_state_changes`
+	var condition []string
+	if option.DeploymentID != "" {
+		condition = append(condition, fmt.Sprintf(`deployment_id = '%s'`, option.DeploymentID))
+	}
+
+	if len(option.State) > 0 {
+		var states []string
+		for _, s := range option.State {
+			states = append(states, strconv.Itoa(int(s)))
+		}
+		condition = append(condition, fmt.Sprintf(`state in (%s)`, strings.Join(states, ",")))
+	}
+
+	if len(condition) > 0 {
+		qry += ` WHERE `
+		qry += strings.Join(condition, ` AND `)
+	}
+
+	if option.Page <= 0 {
+		option.Page = 1
+	}
+
+	if option.Size <= 0 {
+		option.Size = 10
+	}
+
+	offset := (option.Page - 1) * option.Size
+	limit := option.Size
+	qry += fmt.Sprintf(" ORDER BY id DESC LIMIT %d OFFSET %d", limit, offset)
+
+	var out []*types.DeploymentStateChange
+	err := m.db.SelectContext(ctx, &out, qry)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *ManagerDB) GetDeploymentCountByProvider(ctx context.Context, states []types.DeploymentState) (map[types.ProviderID]int, error) {
+	qry := `SELECT provider_id, count(*) as count FROM deployments`
+	if len(states) > 0 {
+		var ss []string
+		for _, s := range states {
+			ss = append(ss, strconv.Itoa(int(s)))
+		}
+		qry += fmt.Sprintf(` WHERE state in (%s)`, strings.Join(ss, ","))
+	}
+	qry += ` GROUP BY provider_id`
+
+	var rows []struct {
+		ProviderID types.ProviderID `db:"provider_id"`
+		Count      int              `db:"count"`
+	}
+	err := m.db.SelectContext(ctx, &rows, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[types.ProviderID]int, len(rows))
+	for _, row := range rows {
+		out[row.ProviderID] = row.Count
+	}
+	return out, nil
+}
+
+// GetDeploymentCountByState returns the number of deployments in every state.
+func (m *ManagerDB) GetDeploymentCountByState(ctx context.Context) (map[types.DeploymentState]int, error) {
+	var rows []struct {
+		State types.DeploymentState `db:"state"`
+		Count int                   `db:"count"`
+	}
+	err := m.db.SelectContext(ctx, &rows, `SELECT state, count(*) as count FROM deployments GROUP BY state`)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[types.DeploymentState]int, len(rows))
+	for _, row := range rows {
+		out[row.State] = row.Count
+	}
+	return out, nil
+}
+
+// AddDeploymentRevision records the spec as the next revision of the deployment and returns the revision number.
+func (m *ManagerDB) AddDeploymentRevision(ctx context.Context, id types.DeploymentID, spec types.DeploymentSpec) (int, error) {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var revision int
+	err = tx.GetContext(ctx, &revision, `SELECT IFNULL(MAX(revision), 0) + 1 FROM deployment_revisions WHERE deployment_id = ? FOR UPDATE`, id)
+	if err != nil {
+		return 0, err
+	}
+
+	qry := `INSERT INTO deployment_revisions (deployment_id, revision, spec, created_at) VALUES (:deployment_id, :revision, :spec, :created_at)`
+	_, err = tx.NamedExecContext(ctx, qry, &types.DeploymentRevision{
+		DeploymentID: id,
+		Revision:     revision,
+		Spec:         spec,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, tx.Commit()
+}
+
+func (m *ManagerDB) GetDeploymentRevisions(ctx context.Context, id types.DeploymentID) ([]*types.DeploymentRevision, error) {
+	var out []*types.DeploymentRevision
+	qry := `SELECT * FROM deployment_revisions WHERE deployment_id = ? ORDER BY revision DESC`
+	if err := m.db.SelectContext(ctx, &out, qry, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *ManagerDB) GetDeploymentRevision(ctx context.Context, id types.DeploymentID, revision int) (*types.DeploymentRevision, error) {
+	var out types.DeploymentRevision
+	qry := `SELECT * FROM deployment_revisions WHERE deployment_id = ? AND revision = ?`
+	if err := m.db.GetContext(ctx, &out, qry, id, revision); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (m *ManagerDB) AddProperties(ctx context.Context, properties *types.Properties) error {
 	qry := `INSERT INTO properties (id, provider_id, app_id, app_type, created_at, updated_at) 
 		        VALUES (:id, :provider_id, :app_id, :app_type, :created_at, :updated_at) ON DUPLICATE KEY UPDATE 
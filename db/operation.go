@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"github.com/gnasnik/titan-container/api/types"
+	"time"
+)
+
+func (m *ManagerDB) AddOperation(ctx context.Context, operation *types.Operation) error {
+	qry := `INSERT INTO operations (id, deployment_id, type, state, error, created_at, updated_at) 
+		        VALUES (:id, :deployment_id, :type, :state, :error, :created_at, :updated_at)`
+	_, err := m.db.NamedExecContext(ctx, qry, operation)
+
+	return err
+}
+
+func (m *ManagerDB) UpdateOperation(ctx context.Context, operation *types.Operation) error {
+	qry := `UPDATE operations SET state = :state, error = :error, updated_at = :updated_at WHERE id = :id`
+	_, err := m.db.NamedExecContext(ctx, qry, operation)
+
+	return err
+}
+
+func (m *ManagerDB) GetOperation(ctx context.Context, id types.OperationID) (*types.Operation, error) {
+	var out types.Operation
+	if err := m.db.GetContext(ctx, &out, `SELECT * FROM operations WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FailRunningOperations fails the operations left running, their work is lost when the manager stops.
+func (m *ManagerDB) FailRunningOperations(ctx context.Context, reason string) error {
+	qry := `UPDATE operations SET state = ?, error = ?, updated_at = ? WHERE state = ?`
+	_, err := m.db.ExecContext(ctx, qry, types.OperationStateFailed, reason, time.Now(), types.OperationStateRunning)
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ManagerDB struct {
@@ -21,9 +22,9 @@ func NewManagerDB(db *sqlx.DB) *ManagerDB {
 }
 
 func (m *ManagerDB) AddNewProvider(ctx context.Context, provider *types.Provider) error {
-	qry := `INSERT INTO providers (id, owner, host_uri, ip, state, created_at, updated_at) 
-		        VALUES (:id, :owner, :host_uri, :ip, :state, :created_at, :updated_at) ON DUPLICATE KEY UPDATE  owner=:owner, host_uri=:host_uri, 
-		            ip=:ip, state=:state, updated_at=:updated_at`
+	qry := `INSERT INTO providers (id, owner, host_uri, ip, state, last_seen, created_at, updated_at) 
+		        VALUES (:id, :owner, :host_uri, :ip, :state, :last_seen, :created_at, :updated_at) ON DUPLICATE KEY UPDATE  owner=:owner, host_uri=:host_uri, 
+		            ip=:ip, state=:state, last_seen=:last_seen, updated_at=:updated_at`
 	_, err := m.db.NamedExecContext(ctx, qry, provider)
 
 	return err
@@ -72,3 +73,101 @@ func (m *ManagerDB) GetAllProviders(ctx context.Context, option *types.GetProvid
 	}
 	return out, nil
 }
+
+func (m *ManagerDB) GetProviderIDsByState(ctx context.Context, states []types.ProviderState) ([]types.ProviderID, error) {
+	qry := `SELECT id from providers`
+	if len(states) > 0 {
+		var ss []string
+		for _, s := range states {
+			ss = append(ss, strconv.Itoa(int(s)))
+		}
+		qry += fmt.Sprintf(` WHERE state in (%s)`, strings.Join(ss, ","))
+	}
+
+	var out []types.ProviderID
+	err := m.db.SelectContext(ctx, &out, qry)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (m *ManagerDB) UpdateProviderState(ctx context.Context, id types.ProviderID, state types.ProviderState, reason string) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE providers SET state = ?, updated_at = ? WHERE id = ?`, state, now, id)
+	if err != nil {
+		return err
+	}
+
+	err = addProviderStateChange(ctx, tx, &types.ProviderStateChange{
+		ProviderID: id,
+		State:      state,
+		Reason:     reason,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addProviderStateChange(ctx context.Context, tx *sqlx.Tx, change *types.ProviderStateChange) error {
+	qry := `INSERT INTO provider_state_changes (provider_id, state, reason, created_at) 
+		        VALUES (:provider_id, :state, :reason, :created_at)`
+	_, err := tx.NamedExecContext(ctx, qry, change)
+
+	return err
+}
+
+func (m *ManagerDB) UpdateProviderLastSeen(ctx context.Context, id types.ProviderID, lastSeen time.Time) error {
+	qry := `UPDATE providers SET last_seen = ? WHERE id = ?`
+	_, err := m.db.ExecContext(ctx, qry, lastSeen, id)
+	return err
+}
+
+func (m *ManagerDB) GetProviderStateChanges(ctx context.Context, option *types.GetProviderOption) ([]*types.ProviderStateChange, error) {
+	qry := `SELECT * from provider_state_changes`
+	var condition []string
+	if option.ID != "" {
+		condition = append(condition, fmt.Sprintf(`provider_id = '%s'`, option.ID))
+	}
+
+	if len(option.State) > 0 {
+		var states []string
+		for _, s := range option.State {
+			states = append(states, strconv.Itoa(int(s)))
+		}
+		condition = append(condition, fmt.Sprintf(`state in (%s)`, strings.Join(states, ",")))
+	}
+
+	if len(condition) > 0 {
+		qry += ` WHERE `
+		qry += strings.Join(condition, ` AND `)
+	}
+
+	if option.Page <= 0 {
+		option.Page = 1
+	}
+
+	if option.Size <= 0 {
+		option.Size = 10
+	}
+
+	offset := (option.Page - 1) * option.Size
+	limit := option.Size
+	qry += fmt.Sprintf(" ORDER BY id DESC LIMIT %d OFFSET %d", limit, offset)
+
+	var out []*types.ProviderStateChange
+	err := m.db.SelectContext(ctx, &out, qry)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gnasnik/titan-container/api/types"
+	"strconv"
+	"strings"
+)
+
+func (m *ManagerDB) SetQuota(ctx context.Context, quota *types.Quota) error {
+	qry := `INSERT INTO quotas (owner, max_deployments, max_cpu, max_memory, max_storage, created_at, updated_at) 
+		        VALUES (:owner, :max_deployments, :max_cpu, :max_memory, :max_storage, :created_at, :updated_at) ON DUPLICATE KEY UPDATE 
+		        max_deployments=:max_deployments, max_cpu=:max_cpu, max_memory=:max_memory, max_storage=:max_storage, updated_at=:updated_at`
+	_, err := m.db.NamedExecContext(ctx, qry, quota)
+
+	return err
+}
+
+// GetQuota returns the quota of the owner, an owner without quota gets an unlimited one.
+func (m *ManagerDB) GetQuota(ctx context.Context, owner string) (*types.Quota, error) {
+	var out types.Quota
+	err := m.db.GetContext(ctx, &out, `SELECT * FROM quotas WHERE owner = ?`, owner)
+	if err == sql.ErrNoRows {
+		return &types.Quota{Owner: owner}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type ownerUsage struct {
+	Deployments int     `db:"deployments"`
+	CPU         float64 `db:"cpu"`
+	Memory      float64 `db:"memory"`
+	Storage     float64 `db:"storage"`
+}
+
+// GetOwnerUsage returns the number of deployments of the owner in the states and the resources requested
+// by all their replicas, the excluded deployment is not counted.
+func (m *ManagerDB) GetOwnerUsage(ctx context.Context, owner string, states []types.DeploymentState, exclude types.DeploymentID) (int, types.ComputeResources, error) {
+	var ss []string
+	for _, s := range states {
+		ss = append(ss, strconv.Itoa(int(s)))
+	}
+
+	qry := fmt.Sprintf(`SELECT COUNT(DISTINCT d.id) AS deployments, 
+			COALESCE(SUM(s.cpu * GREATEST(s.replicas, 1)), 0) AS cpu, 
+			COALESCE(SUM(s.memory * GREATEST(s.replicas, 1)), 0) AS memory, 
+			COALESCE(SUM(s.storage * GREATEST(s.replicas, 1)), 0) AS storage 
+		FROM deployments d LEFT JOIN services s ON d.id = s.deployment_id 
+		WHERE d.owner = ? AND d.id != ? AND d.state in (%s)`, strings.Join(ss, ","))
+
+	var usage ownerUsage
+	err := m.db.GetContext(ctx, &usage, qry, owner, exclude)
+	if err != nil {
+		return 0, types.ComputeResources{}, err
+	}
+
+	return usage.Deployments, types.ComputeResources{
+		CPU:     usage.CPU,
+		Memory:  int64(usage.Memory),
+		Storage: int64(usage.Storage),
+	}, nil
+}
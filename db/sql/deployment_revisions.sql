@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS deployment_revisions(
+    id INT UNSIGNED AUTO_INCREMENT,
+    deployment_id VARCHAR(128) NOT NULL,
+    revision INT NOT NULL,
+    spec TEXT NOT NULL,
+    created_at DATETIME     DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_deployment_revision (deployment_id, revision)
+)ENGINE=InnoDB COMMENT='deployment revisions';
\ No newline at end of file
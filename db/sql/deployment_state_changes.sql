@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS deployment_state_changes(
+    id INT UNSIGNED AUTO_INCREMENT,
+    deployment_id VARCHAR(128) NOT NULL,
+    state INT DEFAULT 0,
+    reason VARCHAR(256) DEFAULT '',
+    created_at DATETIME     DEFAULT NULL,
+    PRIMARY KEY (id),
+    KEY idx_deployment_id (deployment_id)
+)ENGINE=InnoDB COMMENT='deployment state changes';
\ No newline at end of file
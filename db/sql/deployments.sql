@@ -3,12 +3,14 @@ CREATE TABLE IF NOT EXISTS deployments(
     owner VARCHAR(128) NOT NULL,
     name VARCHAR(128) NOT NULL DEFAULT '',
     state INT DEFAULT 0,
+    state_reason VARCHAR(256) DEFAULT '',
     type INT DEFAULT 0,
     authority TINYINT(1) DEFAULT 0,
     version VARCHAR(128) DEFAULT '',
     balance FLOAT        DEFAULT 0,
     cost FLOAT        DEFAULT 0,
     provider_id VARCHAR(128) NOT NULL,
+    registry_credentials BLOB DEFAULT NULL,
     expiration DATETIME     DEFAULT NULL,
     created_at DATETIME     DEFAULT NULL,
     updated_at DATETIME     DEFAULT NULL
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS operations(
+    id VARCHAR(128) NOT NULL,
+    deployment_id VARCHAR(128) NOT NULL,
+    type VARCHAR(32) NOT NULL DEFAULT '',
+    state INT DEFAULT 0,
+    error TEXT,
+    created_at DATETIME     DEFAULT NULL,
+    updated_at DATETIME     DEFAULT NULL,
+    PRIMARY KEY (id),
+    KEY idx_deployment_id (deployment_id)
+)ENGINE=InnoDB COMMENT='deployment operations';
\ No newline at end of file
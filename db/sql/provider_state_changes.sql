@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS provider_state_changes(
+    id INT UNSIGNED AUTO_INCREMENT,
+    provider_id VARCHAR(128) NOT NULL,
+    state INT DEFAULT 0,
+    reason VARCHAR(256) DEFAULT '',
+    created_at DATETIME     DEFAULT NULL,
+    PRIMARY KEY (id),
+    KEY idx_provider_id (provider_id)
+)ENGINE=InnoDB COMMENT='provider state changes';
\ No newline at end of file
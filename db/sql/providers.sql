@@ -4,6 +4,7 @@ CREATE TABLE IF NOT EXISTS providers(
     host_uri VARCHAR(128) NOT NULL,
     ip VARCHAR(128) NOT NULL,
     state INT DEFAULT 0,
+    last_seen DATETIME     DEFAULT NULL,
     created_at DATETIME     DEFAULT NULL,
     updated_at DATETIME     DEFAULT NULL,
     PRIMARY KEY (id)
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS quotas(
+    owner VARCHAR(128) NOT NULL,
+    max_deployments INT DEFAULT 0,
+    max_cpu FLOAT DEFAULT 0,
+    max_memory BIGINT DEFAULT 0,
+    max_storage BIGINT DEFAULT 0,
+    created_at DATETIME     DEFAULT NULL,
+    updated_at DATETIME     DEFAULT NULL,
+    PRIMARY KEY (owner)
+)ENGINE=InnoDB COMMENT='owner quotas';
\ No newline at end of file
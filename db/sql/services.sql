@@ -8,8 +8,12 @@ CREATE TABLE IF NOT EXISTS services(
     cpu FLOAT        DEFAULT 0,
     memory FLOAT        DEFAULT 0,
     storage FLOAT        DEFAULT 0,
+    replicas INT        DEFAULT 1,
+    volumes VARCHAR(1024) DEFAULT NULL,
+    probes VARCHAR(1024) DEFAULT NULL,
     env VARCHAR(128) DEFAULT NULL,
     arguments VARCHAR(128) DEFAULT NULL,
+    encrypted_config BLOB DEFAULT NULL,
     deployment_id VARCHAR(128) NOT NULL,
     error_message VARCHAR(128) DEFAULT NULL,
     created_at DATETIME     DEFAULT NULL,
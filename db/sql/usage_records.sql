@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS usage_records(
+    id BIGINT UNSIGNED AUTO_INCREMENT,
+    deployment_id VARCHAR(128) NOT NULL,
+    owner VARCHAR(128) NOT NULL,
+    provider_id VARCHAR(128) NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME NOT NULL,
+    cpu_seconds DOUBLE DEFAULT 0,
+    memory_byte_seconds DOUBLE DEFAULT 0,
+    storage_byte_seconds DOUBLE DEFAULT 0,
+    used_cpu_seconds DOUBLE DEFAULT 0,
+    used_memory_byte_seconds DOUBLE DEFAULT 0,
+    PRIMARY KEY (id),
+    KEY idx_owner_end_time (owner, end_time),
+    KEY idx_deployment_id (deployment_id)
+)ENGINE=InnoDB COMMENT='deployment usage records';
\ No newline at end of file
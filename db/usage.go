@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"github.com/gnasnik/titan-container/api/types"
+	"time"
+)
+
+// AddUsageRecords stores the usage sampled for the deployments.
+func (m *ManagerDB) AddUsageRecords(ctx context.Context, records []*types.UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	qry := `INSERT INTO usage_records (deployment_id, owner, provider_id, start_time, end_time, cpu_seconds, memory_byte_seconds, 
+		        storage_byte_seconds, used_cpu_seconds, used_memory_byte_seconds) 
+		        VALUES (:deployment_id, :owner, :provider_id, :start_time, :end_time, :cpu_seconds, :memory_byte_seconds, 
+		        :storage_byte_seconds, :used_cpu_seconds, :used_memory_byte_seconds)`
+	_, err := m.db.NamedExecContext(ctx, qry, records)
+
+	return err
+}
+
+// GetUsage returns the usage of the deployments of the owner ended in [from, to) by day and deployment,
+// the usage of all the owners if the owner is empty.
+func (m *ManagerDB) GetUsage(ctx context.Context, owner string, from, to time.Time) ([]*types.Usage, error) {
+	qry := `SELECT DATE_FORMAT(end_time, '%Y-%m-%d') AS day, deployment_id, owner, provider_id, SUM(cpu_seconds) AS cpu_seconds, 
+		        SUM(memory_byte_seconds) AS memory_byte_seconds, SUM(storage_byte_seconds) AS storage_byte_seconds, 
+		        SUM(used_cpu_seconds) AS used_cpu_seconds, SUM(used_memory_byte_seconds) AS used_memory_byte_seconds 
+		        FROM usage_records WHERE end_time >= ? AND end_time < ?`
+	args := []interface{}{from, to}
+
+	if owner != "" {
+		qry += ` AND owner = ?`
+		args = append(args, owner)
+	}
+
+	qry += ` GROUP BY day, deployment_id, owner, provider_id ORDER BY day, deployment_id`
+
+	var out []*types.Usage
+	if err := m.db.SelectContext(ctx, &out, qry, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -259,7 +259,7 @@ func generate(path, pkg, outpkg, outfile string) error {
 						if len(tf) != 2 {
 							continue
 						}
-						if tf[0] != "perm" { // todo: allow more tag types
+						if tf[0] != "perm" && tf[0] != "scope" { // todo: allow more tag types
 							continue
 						}
 						info.Methods[mname].Tags[tf[0]] = tf
@@ -304,7 +304,7 @@ type {{.Name}}Struct struct {
 {{end}}
 	Internal struct {
 {{range .Methods}}
-		{{.Name}} func({{.NamedParams}}) ({{.Results}}) `+"`"+`{{range .Tags}}{{index . 0}}:"{{index . 1}}"{{end}}`+"`"+`
+		{{.Name}} func({{.NamedParams}}) ({{.Results}}) `+"`"+`{{range $k, $v := .Tags}}{{if ne $k "perm"}} {{end}}{{index $v 0}}:"{{index $v 1}}"{{end}}`+"`"+`
 {{end}}
 	}
 }
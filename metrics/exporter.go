@@ -22,7 +22,7 @@ func Exporter() http.Handler {
 	}
 	exporter, err := prometheus.NewExporter(prometheus.Options{
 		Registry:  registry,
-		Namespace: "lotus",
+		Namespace: "titan",
 	})
 	if err != nil {
 		log.Errorf("could not create the prometheus stats exporter: %v", err)
@@ -13,14 +13,9 @@ import (
 
 // Distribution
 var defaultMillisecondsDistribution = view.Distribution(0.01, 0.05, 0.1, 0.3, 0.6, 0.8, 1, 2, 3, 4, 5, 6, 8, 10, 13, 16, 20, 25, 30, 40, 50, 65, 80, 100, 130, 160, 200, 250, 300, 400, 500, 650, 800, 1000, 2000, 3000, 4000, 5000, 7500, 10000, 20000, 50000, 100_000, 250_000, 500_000, 1000_000)
-var workMillisecondsDistribution = view.Distribution(
-	250, 500, 1000, 2000, 5000, 10_000, 30_000, 60_000, 2*60_000, 5*60_000, 10*60_000, 15*60_000, 30*60_000, // short sealing tasks
-	40*60_000, 45*60_000, 50*60_000, 55*60_000, 60*60_000, 65*60_000, 70*60_000, 75*60_000, 80*60_000, 85*60_000, 100*60_000, 120*60_000, // PC2 / C2 range
-	130*60_000, 140*60_000, 150*60_000, 160*60_000, 180*60_000, 200*60_000, 220*60_000, 260*60_000, 300*60_000, // PC1 range
-	350*60_000, 400*60_000, 600*60_000, 800*60_000, 1000*60_000, 1300*60_000, 1800*60_000, 4000*60_000, 10000*60_000, // intel PC1 range
-)
 
-var queueSizeDistribution = view.Distribution(0, 1, 2, 3, 5, 7, 10, 15, 25, 35, 50, 70, 90, 130, 200, 300, 500, 1000, 2000, 5000, 10000)
+// operations apply deployments to kubernetes and wait for the api server, they take from a second to their 30 minutes timeout
+var operationMillisecondsDistribution = view.Distribution(100, 250, 500, 1000, 2000, 5000, 10_000, 20_000, 30_000, 60_000, 2*60_000, 5*60_000, 10*60_000, 15*60_000, 30*60_000)
 
 // Global Tags
 var (
@@ -28,23 +23,38 @@ var (
 	Version, _     = tag.NewKey("version")
 	Commit, _      = tag.NewKey("commit")
 	NodeType, _    = tag.NewKey("node_type")
-	PeerID, _      = tag.NewKey("peer_id")
-	MinerID, _     = tag.NewKey("miner_id")
 	FailureType, _ = tag.NewKey("failure_type")
 
 	Endpoint, _     = tag.NewKey("endpoint")
-	APIInterface, _ = tag.NewKey("api") // to distinguish between gateway api and full node api endpoint calls
+	APIInterface, _ = tag.NewKey("api") // to distinguish between manager api and provider api endpoint calls
+
+	// manager
+	ProviderID, _      = tag.NewKey("provider_id")
+	DeploymentState, _ = tag.NewKey("state")
+	OperationType, _   = tag.NewKey("operation")
+	Resource, _        = tag.NewKey("resource") // cpu, memory or storage
+
+	// provider
+	ObjectKind, _ = tag.NewKey("kind")
 )
 
 // Measures
 var (
 	// common
 	ServiceInfo        = stats.Int64("info", "Arbitrary counter to tag service info to", stats.UnitDimensionless)
-	PeerCount          = stats.Int64("peer/count", "Current number of FIL peers", stats.UnitDimensionless)
 	APIRequestDuration = stats.Float64("api/request_duration_ms", "Duration of API requests", stats.UnitMilliseconds)
 
-	// gateway rate limit
-	RateLimitCount = stats.Int64("ratelimit/limited", "rate limited connections", stats.UnitDimensionless)
+	// manager
+	DeploymentCount             = stats.Int64("deployment/count", "Current number of deployments in a state", stats.UnitDimensionless)
+	DeploymentOperationDuration = stats.Float64("deployment/operation_duration_ms", "Duration of the create, update and close operations of the deployments", stats.UnitMilliseconds)
+	DeploymentOperationFailure  = stats.Int64("deployment/operation_failure", "Counter of failed deployment operations", stats.UnitDimensionless)
+	ProviderCapacity            = stats.Float64("provider/capacity", "Resources of the provider nodes, the cpu in cores, the memory and the storage in bytes", stats.UnitDimensionless)
+	ProviderAllocated           = stats.Float64("provider/allocated", "Resources allocated to the pods of the provider, the cpu in cores, the memory and the storage in bytes", stats.UnitDimensionless)
+	ProviderAvailable           = stats.Float64("provider/available", "Resources of the provider left to allocate, the cpu in cores, the memory and the storage in bytes", stats.UnitDimensionless)
+	ProviderHeartbeatFailure    = stats.Int64("provider/heartbeat_failure", "Counter of failed provider heartbeats", stats.UnitDimensionless)
+
+	// provider
+	KubeApplyFailure = stats.Int64("kube/apply_failure", "Counter of kubernetes objects of the deployments failed to apply", stats.UnitDimensionless)
 )
 
 var (
@@ -56,9 +66,48 @@ var (
 		TagKeys:     []tag.Key{Version, Commit, NodeType},
 	}
 
-	RateLimitedView = &view.View{
-		Measure:     RateLimitCount,
+	// manager
+	DeploymentCountView = &view.View{
+		Measure:     DeploymentCount,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{DeploymentState},
+	}
+	DeploymentOperationDurationView = &view.View{
+		Measure:     DeploymentOperationDuration,
+		Aggregation: operationMillisecondsDistribution,
+		TagKeys:     []tag.Key{OperationType},
+	}
+	DeploymentOperationFailureView = &view.View{
+		Measure:     DeploymentOperationFailure,
 		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{OperationType},
+	}
+	ProviderCapacityView = &view.View{
+		Measure:     ProviderCapacity,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ProviderID, Resource},
+	}
+	ProviderAllocatedView = &view.View{
+		Measure:     ProviderAllocated,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ProviderID, Resource},
+	}
+	ProviderAvailableView = &view.View{
+		Measure:     ProviderAvailable,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ProviderID, Resource},
+	}
+	ProviderHeartbeatFailureView = &view.View{
+		Measure:     ProviderHeartbeatFailure,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{ProviderID},
+	}
+
+	// provider
+	KubeApplyFailureView = &view.View{
+		Measure:     KubeApplyFailure,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{ObjectKind},
 	}
 )
 
@@ -71,13 +120,19 @@ var DefaultViews = func() []*view.View {
 	return views
 }()
 
-var ChainNodeViews = append([]*view.View{}, DefaultViews...)
-
-var MinerNodeViews = append([]*view.View{}, DefaultViews...)
-
-var GatewayNodeViews = append([]*view.View{
-	RateLimitedView,
-}, ChainNodeViews...)
+var ManagerNodeViews = append([]*view.View{
+	DeploymentCountView,
+	DeploymentOperationDurationView,
+	DeploymentOperationFailureView,
+	ProviderCapacityView,
+	ProviderAllocatedView,
+	ProviderAvailableView,
+	ProviderHeartbeatFailureView,
+}, DefaultViews...)
+
+var ProviderNodeViews = append([]*view.View{
+	KubeApplyFailureView,
+}, DefaultViews...)
 
 // SinceInMilliseconds returns the duration of time since the provide time as a float64.
 func SinceInMilliseconds(startTime time.Time) float64 {
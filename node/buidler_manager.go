@@ -47,6 +47,14 @@ func ConfigManager(c interface{}) Option {
 		Override(new(*sqlx.DB), modules.NewManagerDB(cfg.DatabaseAddress)),
 		Override(new(*db.ManagerDB), db.NewManagerDB),
 		Override(new(*manager.ProviderManager), manager.NewProviderScheduler),
+		Override(new(manager.ProviderSelector), manager.NewProviderSelector),
+		Override(new(*manager.Billing), manager.NewBilling),
+		Override(new(*manager.Reconciler), manager.NewReconciler),
+		Override(new(*manager.RolloutWatcher), manager.NewRolloutWatcher),
+		Override(new(*manager.UsageRecorder), manager.NewUsageRecorder),
+		Override(new(*manager.MetricsCollector), manager.NewMetricsCollector),
+		Override(new(*manager.Operations), manager.NewOperations),
+		Override(new(dtypes.DataEncryptionKey), modules.DataEncryptionKey),
 		Override(new(dtypes.SetManagerConfigFunc), modules.NewSetManagerConfigFunc),
 		Override(new(dtypes.GetManagerConfigFunc), modules.NewGetManagerConfigFunc),
 	)
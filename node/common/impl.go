@@ -31,7 +31,9 @@ type CommonAPI struct {
 // MethodGroup: Auth
 
 type jwtPayload struct {
-	Allow []auth.Permission
+	Allow       []auth.Permission
+	Owner       string               `json:",omitempty"`
+	Deployments []types.DeploymentID `json:",omitempty"`
 }
 
 // AuthVerify verifies a JWT token and returns the permissions associated with it
@@ -44,11 +46,18 @@ func (a *CommonAPI) AuthVerify(ctx context.Context, token string) ([]auth.Permis
 	return payload.Allow, nil
 }
 
-// AuthNew generates a new JWT token with the provided permissions
-func (a *CommonAPI) AuthNew(ctx context.Context, perms []auth.Permission) ([]byte, error) {
+// AuthNew generates a new JWT token with the provided permissions, scoped to the owner of the scope
+func (a *CommonAPI) AuthNew(ctx context.Context, perms []auth.Permission, scope *types.AuthScope) ([]byte, error) {
 	p := jwtPayload{
 		Allow: perms, // TODO: consider checking validity
 	}
+	if scope != nil {
+		if scope.Owner == "" {
+			return nil, xerrors.New("token scope requires an owner")
+		}
+		p.Owner = scope.Owner
+		p.Deployments = scope.Deployments
+	}
 
 	return jwt.Sign(&p, (*jwt.HMACSHA)(a.APISecret))
 }
@@ -36,6 +36,14 @@ func DefaultProviderCfg() *ProviderCfg {
 		Owner:   "",
 		HostURI: "",
 		Timeout: "30s",
+
+		Cluster: ClusterCfg{
+			CPUCommitLevel:              1,
+			MemoryCommitLevel:           1,
+			StorageCommitLevel:          1,
+			RollingUpdateMaxSurge:       "25%",
+			RollingUpdateMaxUnavailable: "25%",
+		},
 	}
 }
 
@@ -48,7 +56,25 @@ func DefaultManagerCfg() *ManagerCfg {
 				RemoteListenAddress: "",
 			},
 		},
-		DatabaseAddress: "mysql_user:mysql_password@tcp(127.0.0.1:3306)/titan_container?parseTime=true",
+		DatabaseAddress:        "mysql_user:mysql_password@tcp(127.0.0.1:3306)/titan_container?parseTime=true",
+		ProviderSelectStrategy: "leastloaded",
+		RolloutWatchInterval:   Duration(30 * time.Second),
+		MetricsInterval:        Duration(time.Minute),
+		Billing: BillingCfg{
+			Enabled:             false,
+			Interval:            Duration(5 * time.Minute),
+			LowBalanceThreshold: Duration(24 * time.Hour),
+		},
+		Reconcile: ReconcileCfg{
+			Enabled:        true,
+			Interval:       Duration(5 * time.Minute),
+			GracePeriod:    Duration(10 * time.Minute),
+			GarbageCollect: false,
+		},
+		Usage: UsageCfg{
+			Enabled:  true,
+			Interval: Duration(5 * time.Minute),
+		},
 	}
 }
 
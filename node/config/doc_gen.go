@@ -29,6 +29,105 @@ var Doc = map[string][]DocField{
 			Comment: ``,
 		},
 	},
+	"BillingCfg": []DocField{
+		{
+			Name: "Enabled",
+			Type: "bool",
+
+			Comment: `charge the running deployments every interval, close the expired deployments
+and the deployments that ran out of balance`,
+		},
+		{
+			Name: "Interval",
+			Type: "Duration",
+
+			Comment: `how often the running deployments are charged`,
+		},
+		{
+			Name: "CPUPrice",
+			Type: "float64",
+
+			Comment: `price per hour of a cpu core, a GB of memory and a GB of storage, every replica of a service is charged,
+a deployment with a zero cost is never closed for its balance`,
+		},
+		{
+			Name: "MemoryPrice",
+			Type: "float64",
+
+			Comment: ``,
+		},
+		{
+			Name: "StoragePrice",
+			Type: "float64",
+
+			Comment: ``,
+		},
+		{
+			Name: "LowBalanceThreshold",
+			Type: "Duration",
+
+			Comment: `an alert is raised when the balance of a deployment covers less than this duration`,
+		},
+	},
+	"ClusterCfg": []DocField{
+		{
+			Name: "PublicHostname",
+			Type: "string",
+
+			Comment: `global hostname of the cluster, exposed to the containers as TITAN_CLUSTER_PUBLIC_HOSTNAME`,
+		},
+		{
+			Name: "NetworkPoliciesEnabled",
+			Type: "bool",
+
+			Comment: `install network policies that isolate the namespace of every deployment`,
+		},
+		{
+			Name: "CPUCommitLevel",
+			Type: "float64",
+
+			Comment: `the resources requested by a container are its limits divided by the commit level,
+a level greater than 1 overcommits the resource, 1 or less requests the full limits`,
+		},
+		{
+			Name: "MemoryCommitLevel",
+			Type: "float64",
+
+			Comment: ``,
+		},
+		{
+			Name: "StorageCommitLevel",
+			Type: "float64",
+
+			Comment: ``,
+		},
+		{
+			Name: "RuntimeClass",
+			Type: "string",
+
+			Comment: `runtime class of the deployment pods, e.g. gvisor or kata, the cluster default if empty`,
+		},
+		{
+			Name: "ImagePullSecretsName",
+			Type: "string",
+
+			Comment: `name of the image pull secret used by the deployment pods`,
+		},
+		{
+			Name: "RollingUpdateMaxSurge",
+			Type: "string",
+
+			Comment: `the maximum number of pods that can be created over the desired number of pods during
+a rolling update, an absolute number or a percentage of the desired pods, e.g. "1" or "25%"`,
+		},
+		{
+			Name: "RollingUpdateMaxUnavailable",
+			Type: "string",
+
+			Comment: `the maximum number of pods that can be unavailable during a rolling update,
+an absolute number or a percentage of the desired pods, e.g. "0" or "25%"`,
+		},
+	},
 	"Common": []DocField{
 		{
 			Name: "API",
@@ -37,6 +136,26 @@ var Doc = map[string][]DocField{
 			Comment: ``,
 		},
 	},
+	"IngressCfg": []DocField{
+		{
+			Name: "Enabled",
+			Type: "bool",
+
+			Comment: `create ingresses for the http ports of web deployments, a port is http if it is 80 or has custom hosts`,
+		},
+		{
+			Name: "Domain",
+			Type: "string",
+
+			Comment: `the deployments get the generated host <deployment id>.<Domain>, no host is generated if empty`,
+		},
+		{
+			Name: "ClassName",
+			Type: "string",
+
+			Comment: `ingress class of the ingresses, the cluster default ingress class is used if empty`,
+		},
+	},
 	"ManagerCfg": []DocField{
 		{
 			Name: "DatabaseAddress",
@@ -44,6 +163,43 @@ var Doc = map[string][]DocField{
 
 			Comment: `database address`,
 		},
+		{
+			Name: "ProviderSelectStrategy",
+			Type: "string",
+
+			Comment: `strategy used to pick a provider when a deployment does not specify one,
+one of: binpacking, spread, leastloaded`,
+		},
+		{
+			Name: "RolloutWatchInterval",
+			Type: "Duration",
+
+			Comment: `how often the states of the deployments are refreshed from the rollout status on their providers`,
+		},
+		{
+			Name: "MetricsInterval",
+			Type: "Duration",
+
+			Comment: `how often the deployment counts and the provider resources are recorded to the metrics, disabled if zero`,
+		},
+		{
+			Name: "Billing",
+			Type: "BillingCfg",
+
+			Comment: ``,
+		},
+		{
+			Name: "Reconcile",
+			Type: "ReconcileCfg",
+
+			Comment: `Reconcile configures the comparison of the stored deployments with the providers`,
+		},
+		{
+			Name: "Usage",
+			Type: "UsageCfg",
+
+			Comment: `Usage configures the sampling of the resources used by the deployments`,
+		},
 	},
 	"ProviderCfg": []DocField{
 		{
@@ -52,5 +208,83 @@ var Doc = map[string][]DocField{
 
 			Comment: `used when 'ListenAddress' is unspecified. must be a valid duration recognized by golang's time.ParseDuration function`,
 		},
+		{
+			Name: "Owner",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "HostURI",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "PublicIP",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "KubeConfigPath",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "Cluster",
+			Type: "ClusterCfg",
+
+			Comment: ``,
+		},
+		{
+			Name: "Ingress",
+			Type: "IngressCfg",
+
+			Comment: ``,
+		},
+	},
+	"ReconcileCfg": []DocField{
+		{
+			Name: "Enabled",
+			Type: "bool",
+
+			Comment: `compare the stored deployments with the deployments of the providers every interval`,
+		},
+		{
+			Name: "Interval",
+			Type: "Duration",
+
+			Comment: `how often the providers are reconciled`,
+		},
+		{
+			Name: "GracePeriod",
+			Type: "Duration",
+
+			Comment: `deployments changed more recently than the grace period are not considered as drifted,
+giving the providers time to apply them`,
+		},
+		{
+			Name: "GarbageCollect",
+			Type: "bool",
+
+			Comment: `close the deployments found on a provider that are not active in the manager,
+orphans are only reported if disabled`,
+		},
+	},
+	"UsageCfg": []DocField{
+		{
+			Name: "Enabled",
+			Type: "bool",
+
+			Comment: `record the resources allocated to and used by the running deployments every interval`,
+		},
+		{
+			Name: "Interval",
+			Type: "Duration",
+
+			Comment: `how often the usage of the deployments is sampled`,
+		},
 	},
 }
@@ -21,6 +21,57 @@ type ManagerCfg struct {
 	Common
 	// database address
 	DatabaseAddress string
+	// strategy used to pick a provider when a deployment does not specify one,
+	// one of: binpacking, spread, leastloaded
+	ProviderSelectStrategy string
+	// how often the states of the deployments are refreshed from the rollout status on their providers
+	RolloutWatchInterval Duration
+	// how often the deployment counts and the provider resources are recorded to the metrics, disabled if zero
+	MetricsInterval Duration
+
+	Billing BillingCfg
+	// Reconcile configures the comparison of the stored deployments with the providers
+	Reconcile ReconcileCfg
+	// Usage configures the sampling of the resources used by the deployments
+	Usage UsageCfg
+}
+
+// BillingCfg configures the charging of the running deployments
+type BillingCfg struct {
+	// charge the running deployments every interval, close the expired deployments
+	// and the deployments that ran out of balance
+	Enabled bool
+	// how often the running deployments are charged
+	Interval Duration
+	// price per hour of a cpu core, a GB of memory and a GB of storage, every replica of a service is charged,
+	// a deployment with a zero cost is never closed for its balance
+	CPUPrice     float64
+	MemoryPrice  float64
+	StoragePrice float64
+	// an alert is raised when the balance of a deployment covers less than this duration
+	LowBalanceThreshold Duration
+}
+
+// ReconcileCfg configures the reconciliation of the stored deployments with the workloads of the providers
+type ReconcileCfg struct {
+	// compare the stored deployments with the deployments of the providers every interval
+	Enabled bool
+	// how often the providers are reconciled
+	Interval Duration
+	// deployments changed more recently than the grace period are not considered as drifted,
+	// giving the providers time to apply them
+	GracePeriod Duration
+	// close the deployments found on a provider that are not active in the manager,
+	// orphans are only reported if disabled
+	GarbageCollect bool
+}
+
+// UsageCfg configures the usage records of the running deployments
+type UsageCfg struct {
+	// record the resources allocated to and used by the running deployments every interval
+	Enabled bool
+	// how often the usage of the deployments is sampled
+	Interval Duration
 }
 
 // ProviderCfg provider config
@@ -33,4 +84,40 @@ type ProviderCfg struct {
 	PublicIP string
 
 	KubeConfigPath string
+
+	Cluster ClusterCfg
+	Ingress IngressCfg
+}
+
+// ClusterCfg configures how the kubernetes objects of the deployments are built
+type ClusterCfg struct {
+	// global hostname of the cluster, exposed to the containers as TITAN_CLUSTER_PUBLIC_HOSTNAME
+	PublicHostname string
+	// install network policies that isolate the namespace of every deployment
+	NetworkPoliciesEnabled bool
+	// the resources requested by a container are its limits divided by the commit level,
+	// a level greater than 1 overcommits the resource, 1 or less requests the full limits
+	CPUCommitLevel     float64
+	MemoryCommitLevel  float64
+	StorageCommitLevel float64
+	// runtime class of the deployment pods, e.g. gvisor or kata, the cluster default if empty
+	RuntimeClass string
+	// name of the image pull secret used by the deployment pods
+	ImagePullSecretsName string
+	// the maximum number of pods that can be created over the desired number of pods during
+	// a rolling update, an absolute number or a percentage of the desired pods, e.g. "1" or "25%"
+	RollingUpdateMaxSurge string
+	// the maximum number of pods that can be unavailable during a rolling update,
+	// an absolute number or a percentage of the desired pods, e.g. "0" or "25%"
+	RollingUpdateMaxUnavailable string
+}
+
+// IngressCfg routes the http ports of web deployments through kubernetes ingresses
+type IngressCfg struct {
+	// create ingresses for the http ports of web deployments, a port is http if it is 80 or has custom hosts
+	Enabled bool
+	// the deployments get the generated host <deployment id>.<Domain>, no host is generated if empty
+	Domain string
+	// ingress class of the ingresses, the cluster default ingress class is used if empty
+	ClassName string
 }
@@ -2,9 +2,14 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
 	logging "github.com/ipfs/go-log/v2"
 	"net/http"
+	"strings"
 )
 
 var log = logging.Logger("handler")
@@ -43,5 +48,41 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, RemoteAddr{}, remoteAddr)
 
+	// the auth handler rejects the request if the token fails verification, so the scope is trusted
+	// once the request reaches the api
+	if scope := tokenScope(r); scope != nil {
+		ctx = api.WithAuthScope(ctx, scope)
+	}
+
 	h.handler.ServeHTTP(w, r.WithContext(ctx))
 }
+
+// tokenScope decodes the scope claims from the JWT of the request without verifying it.
+func tokenScope(r *http.Request) *types.AuthScope {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.FormValue("token")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		log.Debugf("decoding token payload: %v", err)
+		return nil
+	}
+
+	var scope types.AuthScope
+	if err := json.Unmarshal(payload, &scope); err != nil {
+		log.Debugf("decoding token claims: %v", err)
+		return nil
+	}
+
+	if scope.Owner == "" {
+		return nil
+	}
+	return &scope
+}
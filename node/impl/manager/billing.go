@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/journal/alerting"
+	"github.com/gnasnik/titan-container/node/config"
+	"go.uber.org/fx"
+)
+
+const lowBalanceAlertSystem = "deployment-balance"
+
+// billableStates are the states of the deployments charged for their resources,
+// the deployments with no pod running yet and the failed ones are not charged.
+var billableStates = map[types.DeploymentState]bool{
+	types.DeploymentStateDeploying: true,
+	types.DeploymentStateRunning:   true,
+	types.DeploymentStateDegraded:  true,
+}
+
+// Billing charges the running deployments for their compute resources every interval, it closes
+// the deployments that expired or ran out of balance and raises an alert for the ones running low.
+type Billing struct {
+	cfg      config.BillingCfg
+	db       *db.ManagerDB
+	pm       *ProviderManager
+	ops      *Operations
+	alerting *alerting.Alerting
+
+	lk         sync.Mutex
+	lowBalance map[types.DeploymentID]alerting.AlertType
+}
+
+// NewBilling creates the billing of the manager, the deployments are only charged if billing is enabled.
+func NewBilling(lc fx.Lifecycle, cfg *config.ManagerCfg, db *db.ManagerDB, pm *ProviderManager, ops *Operations, al *alerting.Alerting) *Billing {
+	b := &Billing{
+		cfg:        cfg.Billing,
+		db:         db,
+		pm:         pm,
+		ops:        ops,
+		alerting:   al,
+		lowBalance: make(map[types.DeploymentID]alerting.AlertType),
+	}
+
+	if !b.cfg.Enabled || b.cfg.Interval <= 0 {
+		return b
+	}
+
+	runEvery(lc, time.Duration(b.cfg.Interval), func(ctx context.Context, _ time.Time) {
+		b.reconcile(ctx)
+	})
+
+	return b
+}
+
+// HourlyCost returns the cost per hour of running the deployment with the configured prices.
+func (b *Billing) HourlyCost(deployment *types.Deployment) float64 {
+	resources := totalResources(deployment)
+	return resources.CPU*b.cfg.CPUPrice +
+		float64(resources.Memory)/1000*b.cfg.MemoryPrice +
+		float64(resources.Storage)/1000*b.cfg.StoragePrice
+}
+
+// reconcile closes the expired deployments and charges the billable ones for one interval.
+func (b *Billing) reconcile(ctx context.Context) {
+	deployments, err := b.db.GetDeploymentsByState(ctx, types.ActiveDeploymentStates)
+	if err != nil {
+		log.Errorf("billing: get active deployments: %v", err)
+		return
+	}
+
+	interval := time.Duration(b.cfg.Interval)
+	for _, deployment := range deployments {
+		if !deployment.Expiration.IsZero() && time.Now().After(deployment.Expiration) {
+			b.close(deployment, "deployment expired")
+			continue
+		}
+
+		if !billableStates[deployment.State] {
+			continue
+		}
+
+		cost := b.HourlyCost(deployment)
+		balance, err := b.db.ChargeDeployment(ctx, deployment.ID, cost, cost*interval.Hours())
+		if err != nil {
+			log.Errorf("billing: charge deployment %s: %v", deployment.ID, err)
+			continue
+		}
+
+		if cost <= 0 {
+			b.resolveLowBalance(deployment.ID)
+			continue
+		}
+
+		if balance <= 0 {
+			b.close(deployment, "deployment balance exhausted")
+			continue
+		}
+
+		remaining := time.Duration(balance / cost * float64(time.Hour))
+		if remaining < time.Duration(b.cfg.LowBalanceThreshold) {
+			b.raiseLowBalance(deployment, balance, remaining)
+		} else {
+			b.resolveLowBalance(deployment.ID)
+		}
+	}
+}
+
+// close closes the deployment as an operation, a deployment being changed is closed by a later reconcile.
+func (b *Billing) close(deployment *types.Deployment, reason string) {
+	log.Infow("billing: closing deployment", "DeploymentID", deployment.ID, "Reason", reason)
+
+	_, err := b.ops.Run(deployment.ID, types.OperationClose, func(ctx context.Context) error {
+		if err := closeDeployment(ctx, b.pm, b.db, deployment, reason); err != nil {
+			return err
+		}
+
+		b.resolveLowBalance(deployment.ID)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("billing: close deployment %s: %v", deployment.ID, err)
+	}
+}
+
+func (b *Billing) raiseLowBalance(deployment *types.Deployment, balance float64, remaining time.Duration) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if _, ok := b.lowBalance[deployment.ID]; ok {
+		return
+	}
+
+	at := b.alerting.AddAlertType(lowBalanceAlertSystem, string(deployment.ID))
+	b.alerting.Raise(at, map[string]interface{}{
+		"DeploymentID": deployment.ID,
+		"Owner":        deployment.Owner,
+		"Balance":      balance,
+		"Remaining":    remaining.String(),
+	})
+	b.lowBalance[deployment.ID] = at
+}
+
+func (b *Billing) resolveLowBalance(id types.DeploymentID) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	at, ok := b.lowBalance[id]
+	if !ok {
+		return
+	}
+
+	b.alerting.Resolve(at, map[string]interface{}{"DeploymentID": id})
+	delete(b.lowBalance, id)
+}
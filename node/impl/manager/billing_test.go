@@ -0,0 +1,22 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingHourlyCost(t *testing.T) {
+	b := &Billing{cfg: config.BillingCfg{CPUPrice: 0.1, MemoryPrice: 0.01, StoragePrice: 0.001}}
+
+	deployment := &types.Deployment{
+		Services: []*types.Service{
+			{ComputeResources: types.ComputeResources{CPU: 1, Memory: 2000, Storage: 10000}, Replicas: 2},
+			{ComputeResources: types.ComputeResources{CPU: 0.5, Memory: 500}},
+		},
+	}
+
+	require.InDelta(t, 2.5*0.1+4.5*0.01+20*0.001, b.HourlyCost(deployment), 1e-9)
+}
@@ -0,0 +1,215 @@
+package manager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// encrypt seals the plaintext with AES-GCM, the random nonce is prepended to the ciphertext.
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decrypt opens a ciphertext produced by encrypt.
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCiphertext, err.Error())
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealRegistryCredentials encrypts the registry credentials of the deployment for storage.
+func sealRegistryCredentials(key []byte, deployment *types.Deployment) error {
+	if len(deployment.RegistryCredentials) == 0 {
+		deployment.EncryptedRegistryCredentials = nil
+		return nil
+	}
+
+	plaintext, err := json.Marshal(deployment.RegistryCredentials)
+	if err != nil {
+		return err
+	}
+
+	deployment.EncryptedRegistryCredentials, err = encrypt(key, plaintext)
+	return err
+}
+
+// openRegistryCredentials decrypts the stored registry credentials of the deployment.
+func openRegistryCredentials(key []byte, deployment *types.Deployment) error {
+	if len(deployment.EncryptedRegistryCredentials) == 0 {
+		deployment.RegistryCredentials = nil
+		return nil
+	}
+
+	plaintext, err := decrypt(key, deployment.EncryptedRegistryCredentials)
+	if err != nil {
+		return errors.Wrapf(err, "decrypting registry credentials of deployment %s", deployment.ID)
+	}
+
+	return json.Unmarshal(plaintext, &deployment.RegistryCredentials)
+}
+
+// serviceConfig is the plaintext form of the encrypted config of a service.
+type serviceConfig struct {
+	Secrets     []types.Secret
+	ConfigFiles []types.ConfigFile
+}
+
+// sealServiceConfigs encrypts the secrets and config files of the services, keyed by service name.
+func sealServiceConfigs(key []byte, services []*types.Service) (map[string][]byte, error) {
+	sealed := make(map[string][]byte)
+	for _, service := range services {
+		if len(service.Secrets) == 0 && len(service.ConfigFiles) == 0 {
+			continue
+		}
+
+		plaintext, err := json.Marshal(serviceConfig{Secrets: service.Secrets, ConfigFiles: service.ConfigFiles})
+		if err != nil {
+			return nil, err
+		}
+
+		sealed[service.Name], err = encrypt(key, plaintext)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sealed, nil
+}
+
+// openServiceConfig decrypts the secrets and config files of the service from the ciphertext.
+func openServiceConfig(key []byte, service *types.Service, ciphertext []byte) error {
+	if len(ciphertext) == 0 {
+		service.Secrets = nil
+		service.ConfigFiles = nil
+		return nil
+	}
+
+	plaintext, err := decrypt(key, ciphertext)
+	if err != nil {
+		return errors.Wrapf(err, "decrypting config of service %s", service.Name)
+	}
+
+	var config serviceConfig
+	if err := json.Unmarshal(plaintext, &config); err != nil {
+		return err
+	}
+
+	service.Secrets = config.Secrets
+	service.ConfigFiles = config.ConfigFiles
+	return nil
+}
+
+// keepServiceConfigs fills the secrets and config files of the services which are left out or redacted in an update
+// with the ones stored for the existing services of the same name.
+func keepServiceConfigs(key []byte, existing, services []*types.Service) error {
+	stored := make(map[string]*types.Service, len(existing))
+	for _, service := range existing {
+		stored[service.Name] = service
+	}
+
+	for _, service := range services {
+		current, ok := stored[service.Name]
+		if !ok || len(current.EncryptedConfig) == 0 {
+			continue
+		}
+
+		config := &types.Service{Name: current.Name}
+		if err := openServiceConfig(key, config, current.EncryptedConfig); err != nil {
+			return err
+		}
+
+		if len(service.Secrets) == 0 {
+			service.Secrets = config.Secrets
+		}
+		for i := range service.Secrets {
+			if service.Secrets[i].Value != "" {
+				continue
+			}
+			for _, secret := range config.Secrets {
+				if secret.Name == service.Secrets[i].Name {
+					service.Secrets[i].Value = secret.Value
+				}
+			}
+		}
+
+		if len(service.ConfigFiles) == 0 {
+			service.ConfigFiles = config.ConfigFiles
+		}
+		for i := range service.ConfigFiles {
+			if service.ConfigFiles[i].Content != "" {
+				continue
+			}
+			for _, configFile := range config.ConfigFiles {
+				if configFile.Name == service.ConfigFiles[i].Name {
+					service.ConfigFiles[i].Content = configFile.Content
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// redactedSecrets returns a copy of the secrets without their values.
+func redactedSecrets(secrets []types.Secret) []types.Secret {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	out := make([]types.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		secret.Value = ""
+		out = append(out, secret)
+	}
+	return out
+}
+
+// redactedConfigFiles returns a copy of the config files without their contents.
+func redactedConfigFiles(configFiles []types.ConfigFile) []types.ConfigFile {
+	if len(configFiles) == 0 {
+		return nil
+	}
+
+	out := make([]types.ConfigFile, 0, len(configFiles))
+	for _, configFile := range configFiles {
+		configFile.Content = ""
+		out = append(out, configFile)
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryCredentialsEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	credentials := []*types.RegistryCredential{{Server: "registry.example.com", Username: "titan", Password: "secret"}}
+	deployment := &types.Deployment{ID: "1", RegistryCredentials: credentials}
+	require.NoError(t, sealRegistryCredentials(key, deployment))
+	require.NotContains(t, string(deployment.EncryptedRegistryCredentials), "secret")
+
+	stored := &types.Deployment{ID: "1", EncryptedRegistryCredentials: deployment.EncryptedRegistryCredentials}
+	require.NoError(t, openRegistryCredentials(key, stored))
+	require.Equal(t, credentials, stored.RegistryCredentials)
+
+	require.ErrorIs(t, openRegistryCredentials(bytes.Repeat([]byte{2}, 32), stored), ErrInvalidCiphertext)
+}
+
+func TestServiceConfigEncryption(t *testing.T) {
+	m := &Manager{DataEncryptionKey: bytes.Repeat([]byte{1}, 32)}
+
+	service := &types.Service{
+		Name:        "web",
+		Secrets:     []types.Secret{{Name: "password", Value: "secret", Env: "PASSWORD"}},
+		ConfigFiles: []types.ConfigFile{{Name: "app.conf", Content: "debug=true", MountPath: "/etc/app.conf"}},
+	}
+	sealed, err := sealServiceConfigs(m.DataEncryptionKey, []*types.Service{service, {Name: "db"}})
+	require.NoError(t, err)
+	require.Len(t, sealed, 1)
+
+	stored := &types.Service{Name: "web", EncryptedConfig: sealed["web"]}
+	require.NoError(t, openServiceConfig(m.DataEncryptionKey, stored, stored.EncryptedConfig))
+	require.Equal(t, service.Secrets, stored.Secrets)
+	require.Equal(t, service.ConfigFiles, stored.ConfigFiles)
+
+	m.redactServiceConfigs([]*types.Service{stored})
+	require.Equal(t, []types.Secret{{Name: "password", Env: "PASSWORD"}}, stored.Secrets)
+	require.Equal(t, []types.ConfigFile{{Name: "app.conf", MountPath: "/etc/app.conf"}}, stored.ConfigFiles)
+	require.Nil(t, stored.EncryptedConfig)
+	require.Equal(t, "secret", service.Secrets[0].Value)
+}
+
+func TestKeepServiceConfigs(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	web := &types.Service{
+		Name:        "web",
+		Secrets:     []types.Secret{{Name: "password", Value: "secret", Env: "PASSWORD"}, {Name: "token", Value: "t0", Env: "TOKEN"}},
+		ConfigFiles: []types.ConfigFile{{Name: "app.conf", Content: "debug=true", MountPath: "/etc/app.conf"}},
+	}
+	sealed, err := sealServiceConfigs(key, []*types.Service{web})
+	require.NoError(t, err)
+	existing := []*types.Service{{Name: "web", EncryptedConfig: sealed["web"]}}
+
+	// left out, the stored secrets and config files are kept
+	services := []*types.Service{{Name: "web"}, {Name: "db"}}
+	require.NoError(t, keepServiceConfigs(key, existing, services))
+	require.Equal(t, web.Secrets, services[0].Secrets)
+	require.Equal(t, web.ConfigFiles, services[0].ConfigFiles)
+	require.Empty(t, services[1].Secrets)
+
+	// the redacted values are filled by name, the new values are kept
+	services = []*types.Service{{
+		Name:        "web",
+		Secrets:     []types.Secret{{Name: "password", Env: "DB_PASSWORD"}, {Name: "token", Value: "t1", Env: "TOKEN"}},
+		ConfigFiles: redactedConfigFiles(web.ConfigFiles),
+	}}
+	require.NoError(t, keepServiceConfigs(key, existing, services))
+	require.Equal(t, []types.Secret{{Name: "password", Value: "secret", Env: "DB_PASSWORD"}, {Name: "token", Value: "t1", Env: "TOKEN"}}, services[0].Secrets)
+	require.Equal(t, web.ConfigFiles, services[0].ConfigFiles)
+}
@@ -2,9 +2,13 @@ package manager
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/gnasnik/titan-container/api"
 	"github.com/gnasnik/titan-container/api/types"
 	"github.com/gnasnik/titan-container/db"
@@ -18,6 +22,9 @@ import (
 
 var log = logging.Logger("manager")
 
+// maxServiceNamePrefix keeps the generated service names within the kubernetes 63 characters limit.
+const maxServiceNamePrefix = 30
+
 // Manager represents a manager service in a cloud computing system.
 type Manager struct {
 	fx.In
@@ -25,7 +32,16 @@ type Manager struct {
 	api.Common
 	DB *db.ManagerDB
 
-	ProviderManager *ProviderManager
+	ProviderManager  *ProviderManager
+	ProviderSelector ProviderSelector
+	Billing          *Billing
+	Reconciler       *Reconciler
+	RolloutWatcher   *RolloutWatcher
+	UsageRecorder    *UsageRecorder
+	MetricsCollector *MetricsCollector
+	Operations       *Operations
+
+	DataEncryptionKey dtypes.DataEncryptionKey
 
 	SetManagerConfigFunc dtypes.SetManagerConfigFunc
 	GetManagerConfigFunc dtypes.GetManagerConfigFunc
@@ -65,15 +81,26 @@ func (m *Manager) ProviderConnect(ctx context.Context, url string, provider *typ
 	}
 
 	provider.State = types.ProviderStateOnline
+	provider.LastSeen = time.Now()
 	provider.CreatedAt = time.Now()
 	provider.UpdatedAt = time.Now()
-	return m.DB.AddNewProvider(ctx, provider)
+	err = m.DB.AddNewProvider(ctx, provider)
+	if err != nil {
+		return err
+	}
+
+	// the inactive deployments of the provider get their state back from the rollout watcher
+	return m.DB.UpdateProviderState(ctx, provider.ID, types.ProviderStateOnline, "connected")
 }
 
 func (m *Manager) GetProviderList(ctx context.Context, opt *types.GetProviderOption) ([]*types.Provider, error) {
 	return m.DB.GetAllProviders(ctx, opt)
 }
 
+func (m *Manager) GetProviderStateChanges(ctx context.Context, opt *types.GetProviderOption) ([]*types.ProviderStateChange, error) {
+	return m.DB.GetProviderStateChanges(ctx, opt)
+}
+
 func (m *Manager) GetDeploymentList(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.Deployment, error) {
 	deployments, err := m.DB.GetDeployments(ctx, opt)
 	if err != nil {
@@ -81,6 +108,8 @@ func (m *Manager) GetDeploymentList(ctx context.Context, opt *types.GetDeploymen
 	}
 
 	for _, deployment := range deployments {
+		m.redactServiceConfigs(deployment.Services)
+
 		providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
 		if err != nil {
 			deployment.State = types.DeploymentStateInActive
@@ -92,31 +121,136 @@ func (m *Manager) GetDeploymentList(ctx context.Context, opt *types.GetDeploymen
 			continue
 		}
 
+		// the services of a deployment not applied yet are the recorded ones
+		if len(remoteDeployment.Services) == 0 {
+			continue
+		}
+
+		configs := make(map[string]*types.Service, len(deployment.Services))
+		for _, service := range deployment.Services {
+			configs[service.Name] = service
+		}
+
+		for _, service := range remoteDeployment.Services {
+			if config, ok := configs[service.Name]; ok {
+				service.Secrets = config.Secrets
+				service.ConfigFiles = config.ConfigFiles
+			}
+		}
+
 		deployment.Services = remoteDeployment.Services
 	}
 
 	return deployments, nil
 }
 
-func (m *Manager) CreateDeployment(ctx context.Context, deployment *types.Deployment) error {
-	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+func (m *Manager) CreateDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error) {
+	if scope := api.GetAuthScope(ctx); scope != nil && deployment.Owner == "" {
+		deployment.Owner = scope.Owner
+	}
+
+	err := m.authorizeOwner(ctx, types.OwnerOperationCreate, deployment.Owner, "", deployment, deployment.Signature)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// TODO: authority validation
+	// the balance and the expiration are credit, the owners only get them through a top up by an admin
+	if (deployment.Balance != 0 || !deployment.Expiration.IsZero()) && !auth.HasPerm(ctx, api.DefaultPerms, api.PermAdmin) {
+		return nil, errors.New("only an admin can set the balance or the expiration of a deployment")
+	}
 
 	deployment.ID = types.DeploymentID(uuid.New().String())
-	deployment.State = types.DeploymentStateActive
+
+	// the quota stays locked until the deployment is recorded
+	unlock := quotaLocks.lock(deployment.Owner)
+	defer unlock()
+
+	err = m.checkQuota(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := false
+	if deployment.ProviderID == "" {
+		providerID, err := m.selectProvider(ctx, deployment)
+		if err != nil {
+			return nil, err
+		}
+		deployment.ProviderID = providerID
+		selected = true
+	}
+
+	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the selected provider has already been checked against the requested resources
+	if !selected {
+		err = m.checkProviderCapacity(ctx, providerApi, scheduledResources(deployment))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deployment.State = types.DeploymentStatePending
+	deployment.StateReason = "waiting for the provider"
+	if deployment.Type == 0 {
+		deployment.Type = types.DeploymentTypeWeb
+	}
+	deployment.Cost = m.Billing.HourlyCost(deployment)
 	deployment.CreatedAt = time.Now()
 	deployment.UpdatedAt = time.Now()
 
-	err = providerApi.CreateDeployment(ctx, deployment)
+	assignServiceNames(nil, deployment.Services)
+
+	// a pod which does not fit on any node of the provider is reported to the caller instead of failing the operation
+	err = providerApi.CheckCapacity(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := m.deploymentSpec(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sealRegistryCredentials(m.DataEncryptionKey, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	// the deployment is recorded before it is applied so that it counts against the quota of the owner
+	for _, service := range deployment.Services {
+		service.DeploymentID = deployment.ID
+		service.EncryptedConfig = spec.EncryptedConfigs[service.Name]
+		service.CreatedAt = deployment.CreatedAt
+		service.UpdatedAt = deployment.UpdatedAt
+	}
+
+	err = m.DB.CreateDeployment(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.runOperation(deployment.ID, types.OperationCreate, func(ctx context.Context) error {
+		return m.applyCreate(ctx, providerApi, deployment, spec)
+	})
+}
+
+// applyCreate creates the recorded deployment on its provider and records the services the provider runs.
+func (m *Manager) applyCreate(ctx context.Context, providerApi api.Provider, deployment *types.Deployment, spec types.DeploymentSpec) error {
+	err := providerApi.CreateDeployment(ctx, deployment)
+	if err != nil {
+		return err
+	}
+
+	err = m.recordRevision(ctx, deployment, spec)
 	if err != nil {
 		return err
 	}
 
-	successDeployment, err := providerApi.GetDeployment(ctx, deployment.ID)
+	successDeployment, err := providerApi.GetAppliedDeployment(ctx, deployment.ID)
 	if err != nil {
 		return err
 	}
@@ -124,52 +258,481 @@ func (m *Manager) CreateDeployment(ctx context.Context, deployment *types.Deploy
 	deployment.Services = successDeployment.Services
 	for _, service := range deployment.Services {
 		service.DeploymentID = deployment.ID
-		service.CreatedAt = time.Now()
+		service.EncryptedConfig = spec.EncryptedConfigs[service.Name]
+		service.CreatedAt = deployment.CreatedAt
 		service.UpdatedAt = time.Now()
 	}
 
-	err = m.DB.CreateDeployment(ctx, deployment)
+	deployment.State = types.DeploymentStateDeploying
+	deployment.StateReason = "created"
+	deployment.UpdatedAt = time.Now()
+	return m.DB.UpdateDeployment(ctx, deployment)
+}
+
+func (m *Manager) UpdateDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error) {
+	deployments, err := m.DB.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: deployment.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deployments) == 0 {
+		return nil, errors.Errorf("deployment %s not found", deployment.ID)
+	}
+	existing := deployments[0]
+
+	err = m.authorizeOwner(ctx, types.OwnerOperationUpdate, existing.Owner, existing.ID, deployment, deployment.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.State == types.DeploymentStateClosing || existing.State == types.DeploymentStateClose {
+		return nil, errors.Errorf("deployment %s is closed", existing.ID)
+	}
+
+	providerApi, err := m.ProviderManager.Get(existing.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignServiceNames(existing.Services, deployment.Services)
+
+	deployment.ProviderID = existing.ProviderID
+	deployment.Owner = existing.Owner
+	deployment.Type = existing.Type
+	if deployment.Type == 0 {
+		deployment.Type = types.DeploymentTypeWeb
+	}
+	deployment.State = types.DeploymentStateDeploying
+	deployment.StateReason = "updated"
+	err = m.checkQuota(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	// the running deployment already holds its resources on the provider, only the increase has to fit
+	if increase := resourcesIncrease(scheduledResources(existing), scheduledResources(deployment)); increase != (types.ComputeResources{}) {
+		err = m.checkProviderCapacity(ctx, providerApi, increase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deployment.Cost = m.Billing.HourlyCost(deployment)
+	deployment.CreatedAt = existing.CreatedAt
+
+	// an update without the values of the secrets and config files, as they are read back redacted, keeps the stored ones
+	err = keepServiceConfigs(m.DataEncryptionKey, existing.Services, deployment.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := m.deploymentSpec(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	// an update without registry credentials keeps pulling with the stored ones
+	if len(deployment.RegistryCredentials) == 0 {
+		err = openRegistryCredentials(m.DataEncryptionKey, existing)
+		if err != nil {
+			return nil, err
+		}
+		deployment.RegistryCredentials = existing.RegistryCredentials
+	}
+
+	return m.runOperation(deployment.ID, types.OperationUpdate, func(ctx context.Context) error {
+		return m.applyUpdate(ctx, providerApi, deployment, spec)
+	})
+}
+
+// applyUpdate updates the deployment on its provider and records the services the provider runs.
+func (m *Manager) applyUpdate(ctx context.Context, providerApi api.Provider, deployment *types.Deployment, spec types.DeploymentSpec) error {
+	err := providerApi.UpdateDeployment(ctx, deployment)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = m.recordRevision(ctx, deployment, spec)
+	if err != nil {
+		return err
+	}
+
+	successDeployment, err := providerApi.GetAppliedDeployment(ctx, deployment.ID)
+	if err != nil {
+		return err
+	}
+
+	deployment.Services = successDeployment.Services
+	for _, service := range deployment.Services {
+		service.DeploymentID = deployment.ID
+		service.EncryptedConfig = spec.EncryptedConfigs[service.Name]
+		service.CreatedAt = deployment.CreatedAt
+		service.UpdatedAt = time.Now()
+	}
+
+	err = sealRegistryCredentials(m.DataEncryptionKey, deployment)
+	if err != nil {
+		return err
+	}
+
+	deployment.UpdatedAt = time.Now()
+	return m.DB.UpdateDeployment(ctx, deployment)
 }
 
-func (m *Manager) UpdateDeployment(ctx context.Context, deployment *types.Deployment) error {
-	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+// runOperation applies the change of the deployment in the background, a deployment whose change
+// could not be applied is failed.
+func (m *Manager) runOperation(id types.DeploymentID, opType types.OperationType, apply func(ctx context.Context) error) (*types.Operation, error) {
+	fail := func(err error) {
+		reason := fmt.Sprintf("%s failed: %v", opType, err)
+		if err := m.DB.UpdateDeploymentState(context.Background(), id, types.DeploymentStateFailed, reason); err != nil {
+			log.Errorf("fail deployment %s: %v", id, err)
+		}
+	}
+
+	operation, err := m.Operations.Run(id, opType, func(ctx context.Context) error {
+		err := apply(ctx)
+		if err != nil && opType != types.OperationClose {
+			fail(err)
+		}
+		return err
+	})
+	if err != nil && opType == types.OperationCreate {
+		fail(err)
+	}
+	return operation, err
+}
+
+func (m *Manager) GetOperation(ctx context.Context, id types.OperationID) (*types.Operation, error) {
+	operation, err := m.Operations.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Errorf("operation %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkDeploymentScope(ctx, operation.DeploymentID); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (m *Manager) WatchOperation(ctx context.Context, id types.OperationID) (<-chan *types.Operation, error) {
+	if _, err := m.GetOperation(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return m.Operations.Watch(ctx, id)
+}
+
+// assignServiceNames keeps the identity of the existing services for the desired services that
+// are not named, a desired service takes the name of the existing service running the same image repository.
+// The desired services left unnamed get a new name, a desired service without replicas keeps the replicas
+// of the existing service of the same name.
+func assignServiceNames(existing, desired []*types.Service) {
+	used := make(map[string]struct{})
+	for _, service := range desired {
+		if service.Name != "" {
+			used[service.Name] = struct{}{}
+		}
+	}
+
+	for _, service := range desired {
+		if service.Name != "" {
+			continue
+		}
+
+		for _, s := range existing {
+			if _, ok := used[s.Name]; ok {
+				continue
+			}
+
+			if imageRepository(s.Image) == imageRepository(service.Image) {
+				service.Name = s.Name
+				used[s.Name] = struct{}{}
+				break
+			}
+		}
+
+		if service.Name == "" {
+			service.Name = newServiceName(service.Image)
+		}
+	}
+
+	replicas := make(map[string]int, len(existing))
+	for _, s := range existing {
+		replicas[s.Name] = s.Replicas
+	}
+	for _, service := range desired {
+		if service.Replicas == 0 {
+			service.Replicas = replicas[service.Name]
+		}
+	}
+}
+
+// newServiceName generates a unique name for a service from the image name.
+func newServiceName(image string) string {
+	name := imageRepository(image)
+	name = strings.ToLower(name[strings.LastIndex(name, "/")+1:])
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+
+	if len(name) > maxServiceNamePrefix {
+		name = name[:maxServiceNamePrefix]
+	}
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "service"
+	}
+
+	return fmt.Sprintf("%s-%s", name, strings.ReplaceAll(uuid.NewString(), "-", ""))
+}
+
+// imageRepository returns the image reference without the tag and digest.
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func (m *Manager) CloseDeployment(ctx context.Context, deployment *types.Deployment) (*types.Operation, error) {
+	existing, err := m.getOwnedDeployment(ctx, types.OwnerOperationClose, deployment, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.State == types.DeploymentStateClosing || existing.State == types.DeploymentStateClose {
+		return nil, errors.Errorf("deployment %s is already closed", existing.ID)
+	}
+
+	return m.runOperation(existing.ID, types.OperationClose, func(ctx context.Context) error {
+		return closeDeployment(ctx, m.ProviderManager, m.DB, existing, "closed by owner")
+	})
+}
+
+// closeDeployment removes the deployment from its provider and marks it closing,
+// the rollout watcher closes it once the provider removed its workloads.
+func closeDeployment(ctx context.Context, pm *ProviderManager, db *db.ManagerDB, deployment *types.Deployment, reason string) error {
+	providerApi, err := pm.Get(deployment.ProviderID)
 	if err != nil {
 		return err
 	}
 
-	err = providerApi.CreateDeployment(ctx, deployment)
+	err = providerApi.CloseDeployment(ctx, deployment)
 	if err != nil {
 		return err
 	}
 
-	err = m.DB.CreateDeployment(ctx, deployment)
+	return db.UpdateDeploymentState(ctx, deployment.ID, types.DeploymentStateClosing, reason)
+}
+
+func (m *Manager) TopUpDeployment(ctx context.Context, id types.DeploymentID, amount float64, duration time.Duration) error {
+	if amount < 0 || duration < 0 {
+		return errors.New("top up amount and duration can not be negative")
+	}
+
+	deployments, err := m.DB.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: id})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if len(deployments) == 0 {
+		return errors.Errorf("deployment %s not found", id)
+	}
+	deployment := deployments[0]
+
+	if deployment.State == types.DeploymentStateClosing || deployment.State == types.DeploymentStateClose {
+		return errors.Errorf("deployment %s is closed", id)
+	}
+
+	// a deployment without expiration never expires, the duration only extends an expiration
+	expiration := deployment.Expiration
+	if !expiration.IsZero() && duration > 0 {
+		if expiration.Before(time.Now()) {
+			expiration = time.Now()
+		}
+		expiration = expiration.Add(duration)
+	}
+
+	return m.DB.TopUpDeployment(ctx, id, amount, expiration)
 }
 
-func (m *Manager) CloseDeployment(ctx context.Context, deployment *types.Deployment) error {
+func (m *Manager) GetDeploymentRevisions(ctx context.Context, id types.DeploymentID) ([]*types.DeploymentRevision, error) {
+	if err := m.checkDeploymentScope(ctx, id); err != nil {
+		return nil, err
+	}
+
+	revisions, err := m.DB.GetDeploymentRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		revision.Spec.EncryptedConfigs = nil
+	}
+	return revisions, nil
+}
+
+func (m *Manager) RollbackDeployment(ctx context.Context, id types.DeploymentID, revision int) (*types.Operation, error) {
+	deploymentRevision, err := m.DB.GetDeploymentRevision(ctx, id, revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Errorf("revision %d of deployment %s not found", revision, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := deploymentFromRevision(m.DataEncryptionKey, deploymentRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.UpdateDeployment(ctx, deployment)
+}
+
+func (m *Manager) GetDeploymentStateChanges(ctx context.Context, opt *types.GetDeploymentOption) ([]*types.DeploymentStateChange, error) {
+	if err := m.checkDeploymentScope(ctx, opt.DeploymentID); err != nil {
+		return nil, err
+	}
+
+	return m.DB.GetDeploymentStateChanges(ctx, opt)
+}
+
+// checkDeploymentScope checks that a scoped api token owns the deployment and is not restricted to other deployments,
+// a scoped token must name the deployment.
+func (m *Manager) checkDeploymentScope(ctx context.Context, id types.DeploymentID) error {
+	scope := api.GetAuthScope(ctx)
+	if scope == nil {
+		return nil
+	}
+
+	if id == "" || !scope.Allows(id) {
+		return ErrNotOwner
+	}
+
+	deployments, err := m.DB.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: id})
+	if err != nil {
+		return err
+	}
+
+	if len(deployments) == 0 {
+		return errors.Errorf("deployment %s not found", id)
+	}
+
+	return checkOwnerScope(ctx, deployments[0])
+}
+
+// deploymentFromRevision returns the deployment described by the revision with the secrets and config files of its services.
+func deploymentFromRevision(key []byte, revision *types.DeploymentRevision) (*types.Deployment, error) {
+	deployment := &types.Deployment{
+		ID:        revision.DeploymentID,
+		Name:      revision.Spec.Name,
+		Authority: revision.Spec.Authority,
+		Services:  revision.Spec.Services,
+	}
+
+	for _, service := range deployment.Services {
+		err := openServiceConfig(key, service, revision.Spec.EncryptedConfigs[service.Name])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deployment, nil
+}
+
+func (m *Manager) ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error {
+	if replicas < 1 {
+		return errors.New("replicas must be at least 1")
+	}
+
+	unlock, err := m.Operations.Lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	deployments, err := m.DB.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: id})
+	if err != nil {
+		return err
+	}
+
+	if len(deployments) == 0 {
+		return errors.Errorf("deployment %s not found", id)
+	}
+	deployment := deployments[0]
+
+	err = checkOwnerScope(ctx, deployment)
+	if err != nil {
+		return err
+	}
+
+	var service *types.Service
+	for _, s := range deployment.Services {
+		if s.Name == serviceName {
+			service = s
+			break
+		}
+	}
+
+	if service == nil {
+		return errors.Errorf("service %s not found in deployment %s", serviceName, id)
+	}
+
 	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
 	if err != nil {
 		return err
 	}
 
-	err = providerApi.CloseDeployment(ctx, deployment)
+	current := service.Replicas
+	if current < 1 {
+		current = 1
+	}
+
+	service.Replicas = replicas
+
+	unlockQuota := quotaLocks.lock(deployment.Owner)
+	defer unlockQuota()
+
+	err = m.checkQuota(ctx, deployment)
+	if err != nil {
+		return err
+	}
+
+	if replicas > current {
+		err = m.checkProviderCapacity(ctx, providerApi, replicasResources(service, replicas-current))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = providerApi.ScaleDeployment(ctx, id, serviceName, replicas)
 	if err != nil {
 		return err
 	}
 
-	return m.DB.UpdateDeploymentState(ctx, deployment.ID, types.DeploymentStateClose)
+	err = m.DB.UpdateServiceReplicas(ctx, id, serviceName, replicas)
+	if err != nil {
+		return err
+	}
+
+	return m.DB.UpdateDeploymentState(ctx, id, types.DeploymentStateDeploying, fmt.Sprintf("service %s scaled to %d replicas", serviceName, replicas))
 }
 
 func (m *Manager) GetLogs(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceLog, error) {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationLogs, deployment, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
 	if err != nil {
 		return nil, err
@@ -178,7 +741,26 @@ func (m *Manager) GetLogs(ctx context.Context, deployment *types.Deployment) ([]
 	return providerApi.GetLogs(ctx, deployment.ID)
 }
 
+func (m *Manager) GetLogStream(ctx context.Context, deployment *types.Deployment, opt *types.LogOption) (<-chan *types.LogLine, error) {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationLogs, deployment, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return providerApi.GetLogStream(ctx, deployment.ID, opt)
+}
+
 func (m *Manager) GetEvents(ctx context.Context, deployment *types.Deployment) ([]*types.ServiceEvent, error) {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationEvents, deployment, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
 	if err != nil {
 		return nil, err
@@ -187,6 +769,68 @@ func (m *Manager) GetEvents(ctx context.Context, deployment *types.Deployment) (
 	return providerApi.GetEvents(ctx, deployment.ID)
 }
 
+func (m *Manager) GetDeploymentMetrics(ctx context.Context, deployment *types.Deployment) (*types.DeploymentMetrics, error) {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationMetrics, deployment, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return providerApi.GetDeploymentMetrics(ctx, deployment.ID)
+}
+
+func (m *Manager) ExecDeployment(ctx context.Context, deployment *types.Deployment, opt *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationExec, deployment, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt != nil {
+		bound := *opt
+		bound.SessionID = execSessionID(deployment.ID, opt.SessionID)
+		opt = &bound
+	}
+	return providerApi.ExecDeployment(ctx, deployment.ID, opt)
+}
+
+func (m *Manager) ExecInput(ctx context.Context, deployment *types.Deployment, input *types.ExecInput) error {
+	deployment, err := m.getOwnedDeployment(ctx, types.OwnerOperationExec, deployment, input)
+	if err != nil {
+		return err
+	}
+
+	providerApi, err := m.ProviderManager.Get(deployment.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	if input == nil {
+		return errors.New("exec input can not empty")
+	}
+
+	bound := *input
+	bound.SessionID = execSessionID(deployment.ID, input.SessionID)
+	return providerApi.ExecInput(ctx, &bound)
+}
+
+// execSessionID binds the exec session of the caller to the deployment, the input of a session
+// is only accepted for the deployment it was started on.
+func execSessionID(id types.DeploymentID, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	return string(id) + "/" + sessionID
+}
+
 func (m *Manager) SetProperties(ctx context.Context, properties *types.Properties) error {
 	_, err := m.ProviderManager.Get(properties.ProviderID)
 	if err != nil {
@@ -199,3 +843,54 @@ func (m *Manager) SetProperties(ctx context.Context, properties *types.Propertie
 }
 
 var _ api.Manager = &Manager{}
+
+// deploymentSpec returns a copy of the desired state of the deployment without the runtime fields of the services,
+// the secrets and config files of the services are kept encrypted in the spec.
+func (m *Manager) deploymentSpec(deployment *types.Deployment) (types.DeploymentSpec, error) {
+	services := make([]*types.Service, 0, len(deployment.Services))
+	for _, service := range deployment.Services {
+		services = append(services, &types.Service{
+			Image:            service.Image,
+			Name:             service.Name,
+			Ports:            service.Ports,
+			Env:              service.Env,
+			Arguments:        service.Arguments,
+			Replicas:         service.Replicas,
+			Volumes:          service.Volumes,
+			Probes:           service.Probes,
+			Secrets:          redactedSecrets(service.Secrets),
+			ConfigFiles:      redactedConfigFiles(service.ConfigFiles),
+			ComputeResources: service.ComputeResources,
+		})
+	}
+
+	encryptedConfigs, err := sealServiceConfigs(m.DataEncryptionKey, deployment.Services)
+	if err != nil {
+		return types.DeploymentSpec{}, err
+	}
+
+	return types.DeploymentSpec{Name: deployment.Name, Authority: deployment.Authority, Services: services, EncryptedConfigs: encryptedConfigs}, nil
+}
+
+// redactServiceConfigs replaces the stored config of the services with their redacted secrets and config files.
+func (m *Manager) redactServiceConfigs(services []*types.Service) {
+	for _, service := range services {
+		if err := openServiceConfig(m.DataEncryptionKey, service, service.EncryptedConfig); err != nil {
+			log.Warnw("failed to open service config", "DeploymentID", service.DeploymentID, "service", service.Name, "error", err)
+		}
+		service.Secrets = redactedSecrets(service.Secrets)
+		service.ConfigFiles = redactedConfigFiles(service.ConfigFiles)
+		service.EncryptedConfig = nil
+	}
+}
+
+// recordRevision records the spec as a new revision of the deployment and sets the deployment version to it.
+func (m *Manager) recordRevision(ctx context.Context, deployment *types.Deployment, spec types.DeploymentSpec) error {
+	revision, err := m.DB.AddDeploymentRevision(ctx, deployment.ID, spec)
+	if err != nil {
+		return err
+	}
+
+	deployment.Version = []byte(strconv.Itoa(revision))
+	return nil
+}
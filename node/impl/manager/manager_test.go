@@ -0,0 +1,28 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignServiceNames(t *testing.T) {
+	existing := []*types.Service{
+		{Name: "web", Image: "nginx:1.24", Replicas: 3},
+		{Name: "db", Image: "redis:7", Replicas: 2},
+	}
+	desired := []*types.Service{
+		{Image: "nginx:1.25"},
+		{Name: "db", Image: "redis:7", Replicas: 1},
+		{Image: "busybox"},
+	}
+
+	assignServiceNames(existing, desired)
+
+	require.Equal(t, "web", desired[0].Name)
+	require.Equal(t, 3, desired[0].Replicas)
+	require.Equal(t, 1, desired[1].Replicas)
+	require.NotEmpty(t, desired[2].Name)
+	require.Equal(t, 0, desired[2].Replicas)
+}
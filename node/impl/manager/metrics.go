@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/metrics"
+	"github.com/gnasnik/titan-container/node/config"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/fx"
+)
+
+// MetricsCollector records the number of deployments in every state and the resources of the connected providers
+// to the metrics every interval.
+type MetricsCollector struct {
+	interval time.Duration
+	db       *db.ManagerDB
+	pm       *ProviderManager
+}
+
+// NewMetricsCollector creates the metrics collector of the manager, a zero interval disables it.
+func NewMetricsCollector(lc fx.Lifecycle, cfg *config.ManagerCfg, db *db.ManagerDB, pm *ProviderManager) *MetricsCollector {
+	c := &MetricsCollector{
+		interval: time.Duration(cfg.MetricsInterval),
+		db:       db,
+		pm:       pm,
+	}
+
+	if c.interval <= 0 {
+		return c
+	}
+
+	runEvery(lc, c.interval, func(ctx context.Context, _ time.Time) {
+		c.collectDeployments(ctx)
+		c.collectProviders(ctx)
+	})
+
+	return c
+}
+
+func (c *MetricsCollector) collectDeployments(ctx context.Context) {
+	counts, err := c.db.GetDeploymentCountByState(ctx)
+	if err != nil {
+		log.Errorf("metrics: get deployment counts: %v", err)
+		return
+	}
+
+	// the states with no deployment are recorded too, the last value of a view is kept until it is recorded again
+	for _, state := range types.AllDeploymentStates {
+		mctx, _ := tag.New(ctx, tag.Upsert(metrics.DeploymentState, types.DeploymentStateString(state)))
+		stats.Record(mctx, metrics.DeploymentCount.M(int64(counts[state])))
+	}
+}
+
+func (c *MetricsCollector) collectProviders(ctx context.Context) {
+	for id, providerApi := range c.pm.GetAll() {
+		sctx, cancel := context.WithTimeout(ctx, statisticsTimeout)
+		statistics, err := providerApi.GetStatistics(sctx)
+		cancel()
+		if err != nil {
+			log.Errorf("metrics: get statistics of provider %s: %v", id, err)
+			continue
+		}
+
+		recordProviderResource(ctx, id, "cpu", statistics.CPUCores.MaxCPUCores, statistics.CPUCores.Active, statistics.CPUCores.Available)
+		recordProviderResource(ctx, id, "memory", float64(statistics.Memory.MaxMemory), float64(statistics.Memory.Active), float64(statistics.Memory.Available))
+		recordProviderResource(ctx, id, "storage", float64(statistics.Storage.MaxStorage), float64(statistics.Storage.Active), float64(statistics.Storage.Available))
+	}
+}
+
+func recordProviderResource(ctx context.Context, id types.ProviderID, resource string, capacity, allocated, available float64) {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.ProviderID, string(id)), tag.Upsert(metrics.Resource, resource))
+	stats.Record(ctx, metrics.ProviderCapacity.M(capacity), metrics.ProviderAllocated.M(allocated), metrics.ProviderAvailable.M(available))
+}
@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/metrics"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/fx"
+)
+
+// operationTimeout bounds the time an operation spends applying a deployment to its provider.
+var operationTimeout = 30 * time.Minute
+
+var ErrOperationInProgress = errors.New("deployment has an operation in progress")
+
+// Operations runs the changes of the deployments in the background, one at a time per deployment,
+// and notifies the watchers of an operation when it finishes.
+type Operations struct {
+	db *db.ManagerDB
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	lk       sync.Mutex
+	running  map[types.DeploymentID]types.OperationID
+	watchers map[types.OperationID]map[chan struct{}]struct{}
+}
+
+// NewOperations creates the operations of the manager, the operations left running by a previous run are failed on start.
+func NewOperations(lc fx.Lifecycle, db *db.ManagerDB) *Operations {
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &Operations{
+		db:       db,
+		ctx:      ctx,
+		cancel:   cancel,
+		running:  make(map[types.DeploymentID]types.OperationID),
+		watchers: make(map[types.OperationID]map[chan struct{}]struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return o.db.FailRunningOperations(ctx, "manager restarted")
+		},
+		OnStop: func(context.Context) error {
+			o.cancel()
+			o.wg.Wait()
+			return nil
+		},
+	})
+
+	return o
+}
+
+// Run records a new operation of the deployment and runs fn in the background, the returned operation is running.
+func (o *Operations) Run(id types.DeploymentID, opType types.OperationType, fn func(ctx context.Context) error) (*types.Operation, error) {
+	o.lk.Lock()
+	if running, ok := o.running[id]; ok {
+		o.lk.Unlock()
+		return nil, operationInProgress(id, running)
+	}
+
+	operation := &types.Operation{
+		ID:           types.OperationID(uuid.New().String()),
+		DeploymentID: id,
+		Type:         opType,
+		State:        types.OperationStateRunning,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	o.running[id] = operation.ID
+	o.lk.Unlock()
+
+	if err := o.db.AddOperation(o.ctx, operation); err != nil {
+		o.lk.Lock()
+		delete(o.running, id)
+		o.lk.Unlock()
+		return nil, err
+	}
+
+	out := *operation
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+
+		mctx, _ := tag.New(o.ctx, tag.Upsert(metrics.OperationType, string(opType)))
+		stop := metrics.Timer(mctx, metrics.DeploymentOperationDuration)
+
+		ctx, cancel := context.WithTimeout(o.ctx, operationTimeout)
+		err := fn(ctx)
+		cancel()
+		stop()
+
+		operation.State = types.OperationStateSucceeded
+		if err != nil {
+			log.Errorw("operation failed", "OperationID", operation.ID, "DeploymentID", id, "Type", opType, "error", err)
+			operation.State = types.OperationStateFailed
+			operation.Error = err.Error()
+			stats.Record(mctx, metrics.DeploymentOperationFailure.M(1))
+		}
+		operation.UpdatedAt = time.Now()
+
+		// the result is recorded even if the manager is stopping
+		if err := o.db.UpdateOperation(context.Background(), operation); err != nil {
+			log.Errorf("update operation %s: %v", operation.ID, err)
+		}
+
+		o.lk.Lock()
+		delete(o.running, id)
+		for watcher := range o.watchers[operation.ID] {
+			select {
+			case watcher <- struct{}{}:
+			default:
+			}
+		}
+		o.lk.Unlock()
+	}()
+
+	return &out, nil
+}
+
+// Lock claims the deployment for a change applied without an operation, it fails with ErrOperationInProgress
+// while the deployment is being changed. The returned func releases the deployment.
+func (o *Operations) Lock(id types.DeploymentID) (func(), error) {
+	o.lk.Lock()
+	defer o.lk.Unlock()
+
+	if running, ok := o.running[id]; ok {
+		return nil, operationInProgress(id, running)
+	}
+
+	// a change without an operation has no operation id to watch
+	o.running[id] = ""
+	return func() {
+		o.lk.Lock()
+		delete(o.running, id)
+		o.lk.Unlock()
+	}, nil
+}
+
+func operationInProgress(id types.DeploymentID, running types.OperationID) error {
+	if running == "" {
+		return errors.Wrapf(ErrOperationInProgress, "deployment %s", id)
+	}
+	return errors.Wrapf(ErrOperationInProgress, "deployment %s, operation %s", id, running)
+}
+
+func (o *Operations) Get(ctx context.Context, id types.OperationID) (*types.Operation, error) {
+	return o.db.GetOperation(ctx, id)
+}
+
+// Watch sends the operation and its finished state once done, the channel is closed after the operation finished.
+func (o *Operations) Watch(ctx context.Context, id types.OperationID) (<-chan *types.Operation, error) {
+	notify := make(chan struct{}, 1)
+	o.lk.Lock()
+	if o.watchers[id] == nil {
+		o.watchers[id] = make(map[chan struct{}]struct{})
+	}
+	o.watchers[id][notify] = struct{}{}
+	o.lk.Unlock()
+
+	unwatch := func() {
+		o.lk.Lock()
+		delete(o.watchers[id], notify)
+		if len(o.watchers[id]) == 0 {
+			delete(o.watchers, id)
+		}
+		o.lk.Unlock()
+	}
+
+	// the watcher is registered before reading the operation so that its end is not missed
+	operation, err := o.db.GetOperation(ctx, id)
+	if err != nil {
+		unwatch()
+		return nil, err
+	}
+
+	out := make(chan *types.Operation)
+	go func() {
+		defer close(out)
+		defer unwatch()
+
+		for {
+			select {
+			case out <- operation:
+			case <-ctx.Done():
+				return
+			}
+
+			if operation.Done() {
+				return
+			}
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+
+			operation, err = o.db.GetOperation(ctx, id)
+			if err != nil {
+				log.Errorf("get operation %s: %v", id, err)
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
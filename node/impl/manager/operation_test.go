@@ -0,0 +1,30 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationsLock(t *testing.T) {
+	o := &Operations{running: make(map[types.DeploymentID]types.OperationID)}
+
+	unlock, err := o.Lock("1")
+	require.NoError(t, err)
+
+	_, err = o.Lock("1")
+	require.ErrorIs(t, err, ErrOperationInProgress)
+
+	_, err = o.Run("1", types.OperationUpdate, nil)
+	require.ErrorIs(t, err, ErrOperationInProgress)
+
+	other, err := o.Lock("2")
+	require.NoError(t, err)
+	other()
+
+	unlock()
+	unlock, err = o.Lock("1")
+	require.NoError(t, err)
+	unlock()
+}
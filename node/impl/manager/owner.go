@@ -0,0 +1,174 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotOwner                = errors.New("the deployment does not belong to the token owner")
+	ErrOwnerSignatureRequired  = errors.New("owner signature required")
+	ErrInvalidOwnerSignature   = errors.New("invalid owner signature")
+	ErrOwnerSignatureExpired   = errors.New("owner signature expired")
+	ErrOwnerSignatureReused    = errors.New("owner signature already used")
+	ErrUnsupportedOwnerKeyType = errors.New("unsupported owner key type")
+)
+
+// signatureMaxAge bounds the clock skew of an owner signature and how long its nonce is remembered.
+var signatureMaxAge = 5 * time.Minute
+
+// usedNonces holds the nonces of the accepted owner signatures until the signatures expire.
+var usedNonces = &nonceCache{used: make(map[string]time.Time)}
+
+type nonceCache struct {
+	lk     sync.Mutex
+	used   map[string]time.Time
+	pruned time.Time
+}
+
+// use records the nonce of the owner until it expires and reports whether it was not recorded yet.
+func (c *nonceCache) use(owner, nonce string, expiry, now time.Time) bool {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if now.Sub(c.pruned) > signatureMaxAge {
+		for key, at := range c.used {
+			if now.After(at) {
+				delete(c.used, key)
+			}
+		}
+		c.pruned = now
+	}
+
+	key := owner + ":" + nonce
+	if _, ok := c.used[key]; ok {
+		return false
+	}
+	c.used[key] = expiry
+	return true
+}
+
+// authorizeOwner checks that the caller may run the operation with the payload on a deployment of the owner.
+// A token scoped to an owner only reaches the deployments of that owner, an unscoped token without the admin
+// permission has to prove the ownership with a signature of the owner key.
+func (m *Manager) authorizeOwner(ctx context.Context, op types.OwnerOperation, owner string, id types.DeploymentID, payload interface{}, signature *types.OwnerSignature) error {
+	if scope := api.GetAuthScope(ctx); scope != nil {
+		if scope.Owner != owner {
+			return ErrNotOwner
+		}
+		return nil
+	}
+
+	if signature == nil {
+		if auth.HasPerm(ctx, api.DefaultPerms, api.PermAdmin) {
+			return nil
+		}
+		return ErrOwnerSignatureRequired
+	}
+
+	return verifyOwnerSignature(op, owner, id, payload, signature)
+}
+
+// verifyOwnerSignature checks that the signature is recent, made for the operation with the payload on the deployment,
+// that the signing key belongs to the owner address and that its nonce was not used before.
+func verifyOwnerSignature(op types.OwnerOperation, owner string, id types.DeploymentID, payload interface{}, signature *types.OwnerSignature) error {
+	if owner == "" {
+		return errors.Wrap(ErrInvalidOwnerSignature, "deployment has no owner")
+	}
+
+	if signature.Nonce == "" {
+		return errors.Wrap(ErrInvalidOwnerSignature, "missing nonce")
+	}
+
+	age := time.Since(time.Unix(signature.Timestamp, 0))
+	if age > signatureMaxAge || age < -signatureMaxAge {
+		return ErrOwnerSignatureExpired
+	}
+
+	pubKey, err := ownerPubKey(signature.KeyType, signature.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	_, address, err := bech32.DecodeAndConvert(owner)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidOwnerSignature, "decoding owner address %s: %v", owner, err)
+	}
+
+	if !bytes.Equal(pubKey.Address(), address) {
+		return errors.Wrap(ErrInvalidOwnerSignature, "public key does not match the owner address")
+	}
+
+	message, err := types.OwnerSignMessage(op, owner, id, signature.Timestamp, signature.Nonce, payload)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidOwnerSignature, "hashing the payload: %v", err)
+	}
+
+	if !pubKey.VerifySignature(message, signature.Signature) {
+		return ErrInvalidOwnerSignature
+	}
+
+	if !usedNonces.use(owner, signature.Nonce, time.Unix(signature.Timestamp, 0).Add(signatureMaxAge), time.Now()) {
+		return ErrOwnerSignatureReused
+	}
+	return nil
+}
+
+func ownerPubKey(keyType types.KeyType, key []byte) (cryptotypes.PubKey, error) {
+	switch keyType {
+	case types.KeyTypeSecp256k1:
+		if len(key) != secp256k1.PubKeySize {
+			return nil, errors.Wrapf(ErrInvalidOwnerSignature, "invalid secp256k1 public key size %d", len(key))
+		}
+		return &secp256k1.PubKey{Key: key}, nil
+	case types.KeyTypeEd25519:
+		if len(key) != ed25519.PubKeySize {
+			return nil, errors.Wrapf(ErrInvalidOwnerSignature, "invalid ed25519 public key size %d", len(key))
+		}
+		return &ed25519.PubKey{Key: key}, nil
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedOwnerKeyType, "%q", keyType)
+	}
+}
+
+// getOwnedDeployment returns the stored deployment once the caller is authorized to run the operation with the payload on it.
+func (m *Manager) getOwnedDeployment(ctx context.Context, op types.OwnerOperation, deployment *types.Deployment, payload interface{}) (*types.Deployment, error) {
+	if deployment.ID == "" {
+		return nil, errors.New("deployment id can not empty")
+	}
+
+	deployments, err := m.DB.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: deployment.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deployments) == 0 {
+		return nil, errors.Errorf("deployment %s not found", deployment.ID)
+	}
+	existing := deployments[0]
+
+	err = m.authorizeOwner(ctx, op, existing.Owner, existing.ID, payload, deployment.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// checkOwnerScope returns ErrNotOwner if the caller token is scoped to another owner than the owner of the deployment.
+func checkOwnerScope(ctx context.Context, deployment *types.Deployment) error {
+	if scope := api.GetAuthScope(ctx); scope != nil && scope.Owner != deployment.Owner {
+		return ErrNotOwner
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func signOwner(t *testing.T, privKey cryptotypes.PrivKey, keyType types.KeyType, op types.OwnerOperation, id types.DeploymentID, payload interface{}, timestamp int64) (string, *types.OwnerSignature) {
+	owner, err := bech32.ConvertAndEncode("titan", privKey.PubKey().Address())
+	require.NoError(t, err)
+
+	nonce := uuid.NewString()
+	message, err := types.OwnerSignMessage(op, owner, id, timestamp, nonce, payload)
+	require.NoError(t, err)
+
+	signature, err := privKey.Sign(message)
+	require.NoError(t, err)
+
+	return owner, &types.OwnerSignature{KeyType: keyType, PublicKey: privKey.PubKey().Bytes(), Signature: signature, Timestamp: timestamp, Nonce: nonce}
+}
+
+func TestVerifyOwnerSignature(t *testing.T) {
+	now := time.Now().Unix()
+
+	for keyType, privKey := range map[types.KeyType]cryptotypes.PrivKey{
+		types.KeyTypeSecp256k1: secp256k1.GenPrivKey(),
+		types.KeyTypeEd25519:   ed25519.GenPrivKey(),
+	} {
+		owner, signature := signOwner(t, privKey, keyType, types.OwnerOperationClose, "1", nil, now)
+
+		// the signature is bound to the operation, the deployment and the owner
+		require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationUpdate, owner, "1", nil, signature), ErrInvalidOwnerSignature)
+		require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationClose, owner, "2", nil, signature), ErrInvalidOwnerSignature)
+		other, _ := signOwner(t, secp256k1.GenPrivKey(), types.KeyTypeSecp256k1, types.OwnerOperationClose, "1", nil, now)
+		require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationClose, other, "1", nil, signature), ErrInvalidOwnerSignature)
+
+		// a signature is accepted once
+		require.NoError(t, verifyOwnerSignature(types.OwnerOperationClose, owner, "1", nil, signature))
+		require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationClose, owner, "1", nil, signature), ErrOwnerSignatureReused)
+
+		owner, signature = signOwner(t, privKey, keyType, types.OwnerOperationClose, "1", nil, now-int64(time.Hour.Seconds()))
+		require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationClose, owner, "1", nil, signature), ErrOwnerSignatureExpired)
+	}
+}
+
+func TestOwnerSignaturePayload(t *testing.T) {
+	privKey := secp256k1.GenPrivKey()
+	deployment := &types.Deployment{ID: "1", Services: []*types.Service{{Name: "web", Image: "nginx"}}}
+
+	// the deployment is signed without its signature
+	owner, signature := signOwner(t, privKey, types.KeyTypeSecp256k1, types.OwnerOperationUpdate, "1", deployment, time.Now().Unix())
+	deployment.Signature = signature
+
+	changed := *deployment
+	changed.Services = []*types.Service{{Name: "web", Image: "attacker/nginx"}}
+	require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationUpdate, owner, "1", &changed, signature), ErrInvalidOwnerSignature)
+	require.NoError(t, verifyOwnerSignature(types.OwnerOperationUpdate, owner, "1", deployment, signature))
+
+	_, signature = signOwner(t, privKey, types.KeyTypeSecp256k1, types.OwnerOperationClose, "1", nil, time.Now().Unix())
+	signature.Nonce = ""
+	require.ErrorIs(t, verifyOwnerSignature(types.OwnerOperationClose, owner, "1", nil, signature), ErrInvalidOwnerSignature)
+}
+
+func TestNonceCache(t *testing.T) {
+	c := &nonceCache{used: make(map[string]time.Time)}
+	now := time.Now()
+
+	require.True(t, c.use("alice", "n1", now.Add(time.Minute), now))
+	require.False(t, c.use("alice", "n1", now.Add(time.Minute), now))
+	require.True(t, c.use("bob", "n1", now.Add(time.Minute), now))
+
+	// the expired nonces are forgotten
+	later := now.Add(2 * signatureMaxAge)
+	require.True(t, c.use("alice", "n1", later.Add(time.Minute), later))
+}
+
+func TestAuthorizeOwner(t *testing.T) {
+	m := &Manager{}
+
+	scoped := api.WithAuthScope(context.Background(), &types.AuthScope{Owner: "alice"})
+	require.NoError(t, m.authorizeOwner(scoped, types.OwnerOperationLogs, "alice", "1", nil, nil))
+	require.ErrorIs(t, m.authorizeOwner(scoped, types.OwnerOperationLogs, "bob", "1", nil, nil), ErrNotOwner)
+
+	admin := auth.WithPerm(context.Background(), api.AllPermissions)
+	require.NoError(t, m.authorizeOwner(admin, types.OwnerOperationLogs, "bob", "1", nil, nil))
+
+	read := auth.WithPerm(context.Background(), api.DefaultPerms)
+	require.ErrorIs(t, m.authorizeOwner(read, types.OwnerOperationLogs, "bob", "1", nil, nil), ErrOwnerSignatureRequired)
+}
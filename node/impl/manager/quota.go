@@ -0,0 +1,121 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/pkg/errors"
+)
+
+// quotaStates are the states of the deployments counted against the owner quota.
+var quotaStates = append([]types.DeploymentState{types.DeploymentStateInActive}, types.ActiveDeploymentStates...)
+
+// quotaLocks serializes the quota check of an owner with the write of the resources it admitted,
+// the parallel requests of an owner can not all pass the check of the same usage.
+var quotaLocks = &ownerLocks{locks: make(map[string]*ownerLock)}
+
+type ownerLocks struct {
+	lk    sync.Mutex
+	locks map[string]*ownerLock
+}
+
+type ownerLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the quota of the owner, the returned func unlocks it.
+func (l *ownerLocks) lock(owner string) func() {
+	l.lk.Lock()
+	ol, ok := l.locks[owner]
+	if !ok {
+		ol = &ownerLock{}
+		l.locks[owner] = ol
+	}
+	ol.refs++
+	l.lk.Unlock()
+
+	ol.Lock()
+	return func() {
+		ol.Unlock()
+
+		l.lk.Lock()
+		ol.refs--
+		if ol.refs == 0 {
+			delete(l.locks, owner)
+		}
+		l.lk.Unlock()
+	}
+}
+
+func (m *Manager) SetQuota(ctx context.Context, quota *types.Quota) error {
+	if quota.MaxDeployments < 0 || quota.MaxCPU < 0 || quota.MaxMemory < 0 || quota.MaxStorage < 0 {
+		return errors.New("quota limits can not be negative")
+	}
+
+	quota.CreatedAt = time.Now()
+	quota.UpdatedAt = time.Now()
+	return m.DB.SetQuota(ctx, quota)
+}
+
+func (m *Manager) GetQuota(ctx context.Context, owner string) (*types.QuotaUsage, error) {
+	quota, err := m.DB.GetQuota(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, used, err := m.DB.GetOwnerUsage(ctx, owner, quotaStates, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.QuotaUsage{Quota: *quota, Deployments: deployments, Used: used}, nil
+}
+
+// checkQuota returns an *api.ErrQuotaExceeded if running the deployment takes its owner over the quota,
+// the stored resources of the deployment are replaced by the requested ones.
+func (m *Manager) checkQuota(ctx context.Context, deployment *types.Deployment) error {
+	quota, err := m.DB.GetQuota(ctx, deployment.Owner)
+	if err != nil {
+		return err
+	}
+
+	deployments, used, err := m.DB.GetOwnerUsage(ctx, deployment.Owner, quotaStates, deployment.ID)
+	if err != nil {
+		return err
+	}
+
+	return quotaExceeded(quota, deployments+1, addResources(used, totalResources(deployment)))
+}
+
+// quotaExceeded compares the requested usage with the quota, a zero limit is unlimited.
+func quotaExceeded(quota *types.Quota, deployments int, request types.ComputeResources) error {
+	limits := []struct {
+		resource  string
+		limit     float64
+		requested float64
+	}{
+		{"deployments", float64(quota.MaxDeployments), float64(deployments)},
+		{"cpu", quota.MaxCPU, request.CPU},
+		{"memory", float64(quota.MaxMemory), float64(request.Memory)},
+		{"storage", float64(quota.MaxStorage), float64(request.Storage)},
+	}
+
+	for _, l := range limits {
+		if l.limit > 0 && l.requested > l.limit {
+			return &api.ErrQuotaExceeded{Owner: quota.Owner, Resource: l.resource, Limit: l.limit, Requested: l.requested}
+		}
+	}
+	return nil
+}
+
+func addResources(a, b types.ComputeResources) types.ComputeResources {
+	return types.ComputeResources{
+		CPU:     a.CPU + b.CPU,
+		Memory:  a.Memory + b.Memory,
+		Storage: a.Storage + b.Storage,
+	}
+}
@@ -0,0 +1,54 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaExceeded(t *testing.T) {
+	quota := &types.Quota{Owner: "alice", MaxDeployments: 2, MaxCPU: 4}
+
+	require.NoError(t, quotaExceeded(quota, 2, types.ComputeResources{CPU: 4, Memory: 1 << 20}))
+	require.NoError(t, quotaExceeded(&types.Quota{Owner: "bob"}, 100, types.ComputeResources{CPU: 100}))
+
+	err := quotaExceeded(quota, 3, types.ComputeResources{CPU: 1})
+	var quotaErr *api.ErrQuotaExceeded
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, "deployments", quotaErr.Resource)
+
+	err = quotaExceeded(quota, 1, types.ComputeResources{CPU: 4.5})
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, &api.ErrQuotaExceeded{Owner: "alice", Resource: "cpu", Limit: 4, Requested: 4.5}, quotaErr)
+}
+
+func TestOwnerLocks(t *testing.T) {
+	l := &ownerLocks{locks: make(map[string]*ownerLock)}
+
+	unlock := l.lock("alice")
+	locked := make(chan struct{})
+	go func() {
+		defer l.lock("alice")()
+		close(locked)
+	}()
+
+	// another owner is not blocked
+	l.lock("bob")()
+
+	select {
+	case <-locked:
+		t.Fatal("the quota of alice was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	<-locked
+	require.Eventually(t, func() bool {
+		l.lk.Lock()
+		defer l.lk.Unlock()
+		return len(l.locks) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,267 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/journal/alerting"
+	"github.com/gnasnik/titan-container/node/config"
+	"github.com/gnasnik/titan-container/node/modules/dtypes"
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
+)
+
+const driftAlertSystem = "deployment-drift"
+
+// reconcileStates are the states of the deployments expected to be running on their provider.
+var reconcileStates = append([]types.DeploymentState{types.DeploymentStateInActive}, types.ActiveDeploymentStates...)
+
+// Drift is the difference between the deployments stored for a provider and the ones running on it.
+type Drift struct {
+	// Missing are stored deployments not found on the provider
+	Missing []*types.Deployment
+	// Orphans are deployments of the provider that are not active in the manager
+	Orphans []*types.Deployment
+}
+
+// Reconciler compares the stored deployments of every provider with the deployments running on it every interval,
+// it re-applies the missing deployments, garbage-collects the orphans if enabled and raises an alert on drift.
+type Reconciler struct {
+	cfg      config.ReconcileCfg
+	db       *db.ManagerDB
+	pm       *ProviderManager
+	ops      *Operations
+	alerting *alerting.Alerting
+	key      []byte
+
+	lk      sync.Mutex
+	drifted map[types.ProviderID]alerting.AlertType
+}
+
+// NewReconciler creates the reconciler of the manager, nothing is compared unless reconciliation is enabled.
+func NewReconciler(lc fx.Lifecycle, cfg *config.ManagerCfg, db *db.ManagerDB, pm *ProviderManager, ops *Operations, al *alerting.Alerting, key dtypes.DataEncryptionKey) *Reconciler {
+	r := &Reconciler{
+		cfg:      cfg.Reconcile,
+		db:       db,
+		pm:       pm,
+		ops:      ops,
+		alerting: al,
+		key:      key,
+		drifted:  make(map[types.ProviderID]alerting.AlertType),
+	}
+
+	if !r.cfg.Enabled || r.cfg.Interval <= 0 {
+		return r
+	}
+
+	runEvery(lc, time.Duration(r.cfg.Interval), func(ctx context.Context, _ time.Time) {
+		r.reconcile(ctx)
+	})
+
+	return r
+}
+
+// reconcile compares the stored deployments with the deployments of every connected provider.
+func (r *Reconciler) reconcile(ctx context.Context) {
+	deployments, err := r.db.GetDeploymentsByState(ctx, reconcileStates)
+	if err != nil {
+		log.Errorf("reconcile: get deployments: %v", err)
+		return
+	}
+
+	byProvider := make(map[types.ProviderID][]*types.Deployment)
+	for _, deployment := range deployments {
+		byProvider[deployment.ProviderID] = append(byProvider[deployment.ProviderID], deployment)
+	}
+
+	for id, providerApi := range r.pm.GetAll() {
+		r.reconcileProvider(ctx, id, providerApi, byProvider[id])
+	}
+}
+
+func (r *Reconciler) reconcileProvider(ctx context.Context, id types.ProviderID, providerApi api.Provider, expected []*types.Deployment) {
+	remote, err := providerApi.ListDeployments(ctx)
+	if err != nil {
+		log.Errorf("reconcile: list deployments of provider %s: %v", id, err)
+		return
+	}
+
+	drift := diffDeployments(expected, remote, time.Now().Add(-time.Duration(r.cfg.GracePeriod)))
+
+	for _, deployment := range drift.Missing {
+		log.Warnw("reconcile: re-applying missing deployment", "ProviderID", id, "DeploymentID", deployment.ID)
+		if err := r.reapply(ctx, providerApi, deployment); err != nil {
+			log.Errorf("reconcile: re-apply deployment %s: %v", deployment.ID, err)
+		}
+	}
+
+	if r.cfg.GarbageCollect {
+		for _, deployment := range drift.Orphans {
+			log.Warnw("reconcile: closing orphan deployment", "ProviderID", id, "DeploymentID", deployment.ID)
+			if err := providerApi.CloseDeployment(ctx, deployment); err != nil {
+				log.Errorf("reconcile: close orphan deployment %s: %v", deployment.ID, err)
+			}
+		}
+	}
+
+	if len(drift.Missing) == 0 && len(drift.Orphans) == 0 {
+		r.resolveDrift(id)
+		return
+	}
+	r.raiseDrift(id, drift)
+}
+
+// diffDeployments returns the drift between the expected deployments of a provider and the ones running on it,
+// deployments changed after the deadline are skipped since the provider may still be applying them.
+// The pending and failed deployments may never have been applied, they are not missing.
+func diffDeployments(expected, remote []*types.Deployment, deadline time.Time) *Drift {
+	running := make(map[types.DeploymentID]*types.Deployment, len(remote))
+	for _, deployment := range remote {
+		running[deployment.ID] = deployment
+	}
+
+	known := make(map[types.DeploymentID]struct{}, len(expected))
+	drift := &Drift{}
+	for _, deployment := range expected {
+		known[deployment.ID] = struct{}{}
+
+		if _, ok := running[deployment.ID]; ok || deployment.UpdatedAt.After(deadline) {
+			continue
+		}
+		if deployment.State == types.DeploymentStatePending || deployment.State == types.DeploymentStateFailed {
+			continue
+		}
+		drift.Missing = append(drift.Missing, deployment)
+	}
+
+	for _, deployment := range remote {
+		if _, ok := known[deployment.ID]; ok {
+			continue
+		}
+		if deployment.State == types.DeploymentStateClosing || deployment.CreatedAt.After(deadline) {
+			continue
+		}
+		drift.Orphans = append(drift.Orphans, deployment)
+	}
+
+	sort.Slice(drift.Orphans, func(i, j int) bool {
+		return drift.Orphans[i].ID < drift.Orphans[j].ID
+	})
+
+	return drift
+}
+
+// reapply creates the deployment on the provider again from its latest revision, a deployment being changed
+// is skipped until the next reconcile.
+func (r *Reconciler) reapply(ctx context.Context, providerApi api.Provider, stored *types.Deployment) error {
+	unlock, err := r.ops.Lock(stored.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the deployment may have been closed or moved since it was listed
+	deployments, err := r.db.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: stored.ID})
+	if err != nil {
+		return err
+	}
+	if len(deployments) == 0 || deployments[0].ProviderID != stored.ProviderID || !reconciled(deployments[0].State) {
+		return nil
+	}
+	stored = deployments[0]
+
+	revisions, err := r.db.GetDeploymentRevisions(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) == 0 {
+		return errors.Errorf("no revision of deployment %s", stored.ID)
+	}
+
+	deployment, err := deploymentFromRevision(r.key, revisions[0])
+	if err != nil {
+		return err
+	}
+
+	// replicas are scaled without a new revision, keep the stored ones
+	replicas := make(map[string]int, len(stored.Services))
+	for _, service := range stored.Services {
+		replicas[service.Name] = service.Replicas
+	}
+	for _, service := range deployment.Services {
+		if n, ok := replicas[service.Name]; ok && n > 0 {
+			service.Replicas = n
+		}
+	}
+
+	deployment.Owner = stored.Owner
+	deployment.Type = stored.Type
+	deployment.ProviderID = stored.ProviderID
+	deployment.EncryptedRegistryCredentials = stored.EncryptedRegistryCredentials
+	err = openRegistryCredentials(r.key, deployment)
+	if err != nil {
+		return err
+	}
+
+	return providerApi.CreateDeployment(ctx, deployment)
+}
+
+// reconciled reports whether the deployment in the state is expected to be running on its provider,
+// the pending and failed deployments may never have been applied.
+func reconciled(state types.DeploymentState) bool {
+	if state == types.DeploymentStatePending || state == types.DeploymentStateFailed {
+		return false
+	}
+
+	for _, s := range reconcileStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reconciler) raiseDrift(id types.ProviderID, drift *Drift) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	at, ok := r.drifted[id]
+	if !ok {
+		at = r.alerting.AddAlertType(driftAlertSystem, string(id))
+		r.drifted[id] = at
+	}
+
+	r.alerting.Raise(at, map[string]interface{}{
+		"ProviderID":     id,
+		"Missing":        deploymentIDs(drift.Missing),
+		"Orphans":        deploymentIDs(drift.Orphans),
+		"GarbageCollect": r.cfg.GarbageCollect,
+	})
+}
+
+func (r *Reconciler) resolveDrift(id types.ProviderID) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	at, ok := r.drifted[id]
+	if !ok {
+		return
+	}
+
+	r.alerting.Resolve(at, map[string]interface{}{"ProviderID": id})
+	delete(r.drifted, id)
+}
+
+func deploymentIDs(deployments []*types.Deployment) []types.DeploymentID {
+	ids := make([]types.DeploymentID, 0, len(deployments))
+	for _, deployment := range deployments {
+		ids = append(ids, deployment.ID)
+	}
+	return ids
+}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDeployments(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(-10 * time.Minute)
+	old := now.Add(-time.Hour)
+
+	expected := []*types.Deployment{
+		{ID: "running", UpdatedAt: old},
+		{ID: "missing", UpdatedAt: old},
+		{ID: "applying", UpdatedAt: now},
+		{ID: "failed", State: types.DeploymentStateFailed, UpdatedAt: old},
+	}
+	remote := []*types.Deployment{
+		{ID: "running", State: types.DeploymentStateRunning, CreatedAt: old},
+		{ID: "orphan", State: types.DeploymentStateRunning, CreatedAt: old},
+		{ID: "terminating", State: types.DeploymentStateClosing, CreatedAt: old},
+		{ID: "new", State: types.DeploymentStateRunning, CreatedAt: now},
+	}
+
+	drift := diffDeployments(expected, remote, deadline)
+	require.Equal(t, []types.DeploymentID{"missing"}, deploymentIDs(drift.Missing))
+	require.Equal(t, []types.DeploymentID{"orphan"}, deploymentIDs(drift.Orphans))
+
+	drift = diffDeployments(expected[:1], remote[:1], deadline)
+	require.Empty(t, drift.Missing)
+	require.Empty(t, drift.Orphans)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gnasnik/titan-container/api/client"
 	"golang.org/x/xerrors"
 	"net/http"
+	"net/url"
 )
 
 type remoteProvider struct {
@@ -16,14 +17,20 @@ type remoteProvider struct {
 }
 
 func connectRemoteProvider(ctx context.Context, fa api.Common, url string) (*remoteProvider, error) {
-	token, err := fa.AuthNew(ctx, []auth.Permission{"read", "admin"})
+	token, err := fa.AuthNew(ctx, []auth.Permission{"read", "admin"}, nil)
 	if err != nil {
 		return nil, xerrors.Errorf("creating auth token for remote connection: %w", err)
 	}
 	headers := http.Header{}
 	headers.Add("Authorization", "Bearer "+string(token))
 
-	papi, closer, err := client.NewProvider(context.TODO(), url, headers)
+	// streaming methods return channels, which are only supported over websocket
+	addr, err := websocketURL(url)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing provider url: %w", err)
+	}
+
+	papi, closer, err := client.NewProvider(context.TODO(), addr, headers)
 	if err != nil {
 		return nil, xerrors.Errorf("creating jsonrpc client: %w", err)
 	}
@@ -41,6 +48,21 @@ func connectRemoteProvider(ctx context.Context, fa api.Common, url string) (*rem
 	return &remoteProvider{papi, closer}, nil
 }
 
+func websocketURL(addr string) (string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	return u.String(), nil
+}
+
 func (r *remoteProvider) Close() error {
 	r.closer()
 	return nil
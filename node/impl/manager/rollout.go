@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/node/config"
+	"go.uber.org/fx"
+)
+
+// watchStates are the states of the deployments whose state follows the rollout status on their provider.
+var watchStates = append([]types.DeploymentState{types.DeploymentStateInActive, types.DeploymentStateClosing}, types.ActiveDeploymentStates...)
+
+// RolloutWatcher moves the deployments of the connected providers through their states
+// from the rollout status of their workloads. The changes pushed by the providers are applied as they come,
+// every interval the watches of the newly connected providers are started and all the states are refreshed.
+type RolloutWatcher struct {
+	interval time.Duration
+	db       *db.ManagerDB
+	pm       *ProviderManager
+
+	wg       sync.WaitGroup
+	lk       sync.Mutex
+	watching map[types.ProviderID]struct{}
+}
+
+// NewRolloutWatcher creates the rollout watcher of the manager.
+func NewRolloutWatcher(lc fx.Lifecycle, cfg *config.ManagerCfg, db *db.ManagerDB, pm *ProviderManager) *RolloutWatcher {
+	w := &RolloutWatcher{
+		interval: time.Duration(cfg.RolloutWatchInterval),
+		db:       db,
+		pm:       pm,
+		watching: make(map[types.ProviderID]struct{}),
+	}
+
+	if w.interval <= 0 {
+		return w
+	}
+
+	// the hooks stop in reverse order, the watches end once the refresh loop is cancelled
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			w.wg.Wait()
+			return nil
+		},
+	})
+	runEvery(lc, w.interval, func(ctx context.Context, _ time.Time) {
+		w.watch(ctx)
+		w.refresh(ctx)
+	})
+
+	return w
+}
+
+// watch follows the deployment changes pushed by the connected providers which are not watched yet.
+func (w *RolloutWatcher) watch(ctx context.Context) {
+	for id, providerApi := range w.pm.GetAll() {
+		w.lk.Lock()
+		_, ok := w.watching[id]
+		w.watching[id] = struct{}{}
+		w.lk.Unlock()
+
+		if ok {
+			continue
+		}
+
+		changes, err := providerApi.WatchDeployments(ctx)
+		if err != nil {
+			log.Errorf("rollout: watch deployments of provider %s: %v", id, err)
+			w.unwatch(id)
+			continue
+		}
+
+		w.wg.Add(1)
+		go w.follow(ctx, id, changes)
+	}
+}
+
+func (w *RolloutWatcher) unwatch(id types.ProviderID) {
+	w.lk.Lock()
+	delete(w.watching, id)
+	w.lk.Unlock()
+}
+
+// follow applies the deployment changes of the provider until the provider disconnects.
+func (w *RolloutWatcher) follow(ctx context.Context, id types.ProviderID, changes <-chan *types.Deployment) {
+	defer w.wg.Done()
+	defer w.unwatch(id)
+
+	for observed := range changes {
+		deployments, err := w.db.GetDeployments(ctx, &types.GetDeploymentOption{DeploymentID: observed.ID, State: watchStates})
+		if err != nil {
+			log.Errorf("rollout: get deployment %s: %v", observed.ID, err)
+			continue
+		}
+
+		if len(deployments) == 0 || deployments[0].ProviderID != id {
+			continue
+		}
+
+		// the namespace of a closed deployment is gone from the provider
+		if observed.State == types.DeploymentStateClose {
+			observed = nil
+		}
+		w.update(ctx, deployments[0], observed)
+	}
+}
+
+// refresh updates the states of the deployments of every connected provider.
+func (w *RolloutWatcher) refresh(ctx context.Context) {
+	deployments, err := w.db.GetDeploymentsByState(ctx, watchStates)
+	if err != nil {
+		log.Errorf("rollout: get deployments: %v", err)
+		return
+	}
+
+	byProvider := make(map[types.ProviderID][]*types.Deployment)
+	for _, deployment := range deployments {
+		byProvider[deployment.ProviderID] = append(byProvider[deployment.ProviderID], deployment)
+	}
+
+	for id, providerApi := range w.pm.GetAll() {
+		if len(byProvider[id]) == 0 {
+			continue
+		}
+		w.refreshProvider(ctx, id, providerApi, byProvider[id])
+	}
+}
+
+func (w *RolloutWatcher) refreshProvider(ctx context.Context, id types.ProviderID, providerApi api.Provider, deployments []*types.Deployment) {
+	remote, err := providerApi.ListDeployments(ctx)
+	if err != nil {
+		log.Errorf("rollout: list deployments of provider %s: %v", id, err)
+		return
+	}
+
+	observed := make(map[types.DeploymentID]*types.Deployment, len(remote))
+	for _, deployment := range remote {
+		observed[deployment.ID] = deployment
+	}
+
+	for _, deployment := range deployments {
+		w.update(ctx, deployment, observed[deployment.ID])
+	}
+}
+
+// update moves the deployment to the state following the deployment observed on its provider.
+func (w *RolloutWatcher) update(ctx context.Context, deployment *types.Deployment, observed *types.Deployment) {
+	state, reason := nextDeploymentState(deployment.State, observed)
+	if state == deployment.State {
+		return
+	}
+
+	// the deployment may have been closed or changed while its provider was observed
+	moved, err := w.db.UpdateDeploymentStateFrom(ctx, deployment.ID, deployment.State, state, reason)
+	if err != nil {
+		log.Errorf("rollout: update state of deployment %s: %v", deployment.ID, err)
+		return
+	}
+	if !moved {
+		return
+	}
+
+	log.Infow("deployment state changed", "DeploymentID", deployment.ID,
+		"From", types.DeploymentStateString(deployment.State), "To", types.DeploymentStateString(state), "Reason", reason)
+}
+
+// nextDeploymentState returns the state a deployment moves to from the deployment observed on its provider,
+// the observed deployment is nil if the provider has no workload for it.
+func nextDeploymentState(current types.DeploymentState, observed *types.Deployment) (types.DeploymentState, string) {
+	if current == types.DeploymentStateClosing {
+		if observed == nil {
+			return types.DeploymentStateClose, "removed from provider"
+		}
+		return current, ""
+	}
+
+	// a missing deployment is re-applied by the reconciler
+	if observed == nil || observed.State == types.DeploymentStateClosing {
+		return current, ""
+	}
+
+	switch {
+	// a deployment only degrades once it ran
+	case observed.State == types.DeploymentStateDegraded && (current == types.DeploymentStatePending || current == types.DeploymentStateDeploying):
+		return types.DeploymentStateDeploying, observed.StateReason
+	// and a deployment that ran is not pending again when its pods are rescheduled
+	case observed.State == types.DeploymentStatePending && (current == types.DeploymentStateRunning || current == types.DeploymentStateDegraded):
+		return types.DeploymentStateDegraded, observed.StateReason
+	}
+
+	return observed.State, observed.StateReason
+}
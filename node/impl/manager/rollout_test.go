@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDeploymentState(t *testing.T) {
+	observed := func(state types.DeploymentState) *types.Deployment {
+		return &types.Deployment{State: state}
+	}
+
+	cases := []struct {
+		current  types.DeploymentState
+		observed *types.Deployment
+		expect   types.DeploymentState
+	}{
+		{types.DeploymentStateDeploying, observed(types.DeploymentStateRunning), types.DeploymentStateRunning},
+		{types.DeploymentStateDeploying, observed(types.DeploymentStateDegraded), types.DeploymentStateDeploying},
+		{types.DeploymentStatePending, observed(types.DeploymentStateDeploying), types.DeploymentStateDeploying},
+		{types.DeploymentStateRunning, observed(types.DeploymentStateDegraded), types.DeploymentStateDegraded},
+		{types.DeploymentStateRunning, observed(types.DeploymentStatePending), types.DeploymentStateDegraded},
+		{types.DeploymentStateDeploying, observed(types.DeploymentStateFailed), types.DeploymentStateFailed},
+		{types.DeploymentStateInActive, observed(types.DeploymentStateDegraded), types.DeploymentStateDegraded},
+		{types.DeploymentStateRunning, nil, types.DeploymentStateRunning},
+		{types.DeploymentStateRunning, observed(types.DeploymentStateClosing), types.DeploymentStateRunning},
+		{types.DeploymentStateClosing, observed(types.DeploymentStateClosing), types.DeploymentStateClosing},
+		{types.DeploymentStateClosing, nil, types.DeploymentStateClose},
+	}
+
+	for _, c := range cases {
+		state, _ := nextDeploymentState(c.current, c.observed)
+		require.Equal(t, types.DeploymentStateString(c.expect), types.DeploymentStateString(state),
+			"from %s", types.DeploymentStateString(c.current))
+	}
+}
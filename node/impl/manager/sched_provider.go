@@ -7,7 +7,11 @@ import (
 
 	"github.com/gnasnik/titan-container/api"
 	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/metrics"
 	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 )
 
 var HeartbeatInterval = 10 * time.Second
@@ -21,11 +25,14 @@ var (
 type ProviderManager struct {
 	lk        sync.RWMutex
 	providers map[types.ProviderID]*providerLife
+
+	db *db.ManagerDB
 }
 
 type providerLife struct {
 	api.Provider
 	LastSeen time.Time
+	State    types.ProviderState
 }
 
 func (p *providerLife) Update() {
@@ -39,9 +46,15 @@ func (p *providerLife) Expired() bool {
 	return false
 }
 
-func NewProviderScheduler() *ProviderManager {
+func NewProviderScheduler(db *db.ManagerDB) *ProviderManager {
 	s := &ProviderManager{
 		providers: make(map[types.ProviderID]*providerLife),
+		db:        db,
+	}
+
+	// providers left online by a previous run have to reconnect before they are usable
+	if err := s.resetProviderStates(context.Background()); err != nil {
+		log.Errorf("reset provider states: %v", err)
 	}
 
 	go s.watch()
@@ -50,7 +63,7 @@ func NewProviderScheduler() *ProviderManager {
 
 func (p *ProviderManager) AddProvider(id types.ProviderID, providerApi api.Provider) error {
 	p.lk.Lock()
-	p.lk.Unlock()
+	defer p.lk.Unlock()
 
 	_, exist := p.providers[id]
 	if exist {
@@ -60,6 +73,7 @@ func (p *ProviderManager) AddProvider(id types.ProviderID, providerApi api.Provi
 	p.providers[id] = &providerLife{
 		Provider: providerApi,
 		LastSeen: time.Now(),
+		State:    types.ProviderStateOnline,
 	}
 	return nil
 }
@@ -77,6 +91,18 @@ func (p *ProviderManager) Get(id types.ProviderID) (api.Provider, error) {
 	return provider, nil
 }
 
+// GetAll returns all the connected providers.
+func (p *ProviderManager) GetAll() map[types.ProviderID]api.Provider {
+	p.lk.RLock()
+	defer p.lk.RUnlock()
+
+	out := make(map[types.ProviderID]api.Provider, len(p.providers))
+	for id, provider := range p.providers {
+		out[id] = provider
+	}
+	return out
+}
+
 func (p *ProviderManager) delProvider(id types.ProviderID) {
 	p.lk.Lock()
 	defer p.lk.Unlock()
@@ -98,26 +124,92 @@ func (p *ProviderManager) watch() {
 		case <-heartbeatTimer.C:
 		}
 
-		sctx, scancel := context.WithTimeout(ctx, HeartbeatInterval/2)
+		p.lk.RLock()
+		providers := make(map[types.ProviderID]*providerLife, len(p.providers))
+		for id, provider := range p.providers {
+			providers[id] = provider
+		}
+		p.lk.RUnlock()
 
+		for id, provider := range providers {
+			p.checkProvider(ctx, id, provider)
+		}
+	}
+}
+
+// checkProvider checks the session of a provider and persists the state transitions of it.
+func (p *ProviderManager) checkProvider(ctx context.Context, id types.ProviderID, provider *providerLife) {
+	sctx, scancel := context.WithTimeout(ctx, HeartbeatInterval/2)
+	_, err := provider.Session(sctx)
+	scancel()
+
+	if err == nil {
 		p.lk.Lock()
-		for id, provider := range p.providers {
-			_, err := provider.Session(sctx)
-			scancel()
-			if err != nil {
-				if !provider.Expired() {
-					// Likely temporary error
-					log.Warnw("failed to check provider session", "error", err)
-					continue
-				}
-
-				log.Warnw("Provider closing", "ProviderID", id)
-				delete(p.providers, id)
-				continue
-			}
-			provider.Update()
+		provider.Update()
+		lastSeen, state := provider.LastSeen, provider.State
+		provider.State = types.ProviderStateOnline
+		p.lk.Unlock()
+
+		if state != types.ProviderStateOnline {
+			p.setProviderState(ctx, id, types.ProviderStateOnline, "heartbeat recovered")
 		}
+
+		if err := p.db.UpdateProviderLastSeen(ctx, id, lastSeen); err != nil {
+			log.Errorf("update provider %s last seen: %v", id, err)
+		}
+		return
+	}
+
+	mctx, _ := tag.New(ctx, tag.Upsert(metrics.ProviderID, string(id)))
+	stats.Record(mctx, metrics.ProviderHeartbeatFailure.M(1))
+
+	if !provider.Expired() {
+		// Likely temporary error
+		log.Warnw("failed to check provider session", "error", err)
+
+		p.lk.Lock()
+		state := provider.State
+		provider.State = types.ProviderStateAbnormal
 		p.lk.Unlock()
 
+		if state != types.ProviderStateAbnormal {
+			p.setProviderState(ctx, id, types.ProviderStateAbnormal, err.Error())
+		}
+		return
 	}
+
+	log.Warnw("Provider closing", "ProviderID", id)
+	p.delProvider(id)
+	p.setProviderState(ctx, id, types.ProviderStateOffline, "heartbeat expired")
+
+	err = p.db.UpdateDeploymentStateByProvider(ctx, id, types.ActiveDeploymentStates, types.DeploymentStateInActive, "provider offline")
+	if err != nil {
+		log.Errorf("inactive deployments of provider %s: %v", id, err)
+	}
+}
+
+func (p *ProviderManager) setProviderState(ctx context.Context, id types.ProviderID, state types.ProviderState, reason string) {
+	log.Infow("provider state changed", "ProviderID", id, "State", types.ProviderStateString(state), "Reason", reason)
+
+	if err := p.db.UpdateProviderState(ctx, id, state, reason); err != nil {
+		log.Errorf("update provider %s state: %v", id, err)
+	}
+}
+
+func (p *ProviderManager) resetProviderStates(ctx context.Context) error {
+	ids, err := p.db.GetProviderIDsByState(ctx, []types.ProviderState{types.ProviderStateOnline, types.ProviderStateAbnormal})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		p.setProviderState(ctx, id, types.ProviderStateOffline, "manager restarted")
+
+		err = p.db.UpdateDeploymentStateByProvider(ctx, id, types.ActiveDeploymentStates, types.DeploymentStateInActive, "manager restarted")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,272 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/config"
+	"github.com/pkg/errors"
+)
+
+// SelectStrategy is the name of a provider selection strategy.
+type SelectStrategy string
+
+const (
+	// SelectStrategyBinPacking places a deployment on the provider that has the least
+	// free capacity left after placement, keeping other providers free for large requests.
+	SelectStrategyBinPacking SelectStrategy = "binpacking"
+	// SelectStrategySpread places a deployment on the provider running the fewest deployments.
+	SelectStrategySpread SelectStrategy = "spread"
+	// SelectStrategyLeastLoaded places a deployment on the provider with the lowest resource utilization.
+	SelectStrategyLeastLoaded SelectStrategy = "leastloaded"
+)
+
+var (
+	ErrNoAvailableProvider   = errors.New("no available provider")
+	ErrUnknownStrategy       = errors.New("unknown provider select strategy")
+	ErrInsufficientResources = errors.New("insufficient provider resources")
+)
+
+// statisticsTimeout bounds the time spent fetching the statistics of a single provider.
+var statisticsTimeout = 5 * time.Second
+
+// ProviderCandidate is a connected provider considered for a deployment.
+type ProviderCandidate struct {
+	ID          types.ProviderID
+	Statistics  *types.ResourcesStatistics
+	Deployments int
+}
+
+// ProviderSelector chooses the provider a deployment is placed on.
+type ProviderSelector interface {
+	Select(candidates []*ProviderCandidate, request types.ComputeResources) (types.ProviderID, error)
+}
+
+// NewProviderSelector returns the provider selector configured for the manager.
+func NewProviderSelector(cfg *config.ManagerCfg) (ProviderSelector, error) {
+	return NewProviderSelectorByStrategy(SelectStrategy(cfg.ProviderSelectStrategy))
+}
+
+// NewProviderSelectorByStrategy returns the provider selector for the given strategy,
+// an empty strategy falls back to SelectStrategyLeastLoaded.
+func NewProviderSelectorByStrategy(strategy SelectStrategy) (ProviderSelector, error) {
+	switch strategy {
+	case SelectStrategyBinPacking:
+		return &binPackingSelector{}, nil
+	case SelectStrategySpread:
+		return &spreadSelector{}, nil
+	case SelectStrategyLeastLoaded, "":
+		return &leastLoadedSelector{}, nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownStrategy, "%q", strategy)
+	}
+}
+
+type binPackingSelector struct{}
+
+func (s *binPackingSelector) Select(candidates []*ProviderCandidate, request types.ComputeResources) (types.ProviderID, error) {
+	return selectBy(candidates, request, func(a, b *ProviderCandidate) bool {
+		return remainingRatio(a.Statistics, request) < remainingRatio(b.Statistics, request)
+	})
+}
+
+type spreadSelector struct{}
+
+func (s *spreadSelector) Select(candidates []*ProviderCandidate, request types.ComputeResources) (types.ProviderID, error) {
+	return selectBy(candidates, request, func(a, b *ProviderCandidate) bool {
+		if a.Deployments != b.Deployments {
+			return a.Deployments < b.Deployments
+		}
+		return utilization(a.Statistics) < utilization(b.Statistics)
+	})
+}
+
+type leastLoadedSelector struct{}
+
+func (s *leastLoadedSelector) Select(candidates []*ProviderCandidate, request types.ComputeResources) (types.ProviderID, error) {
+	return selectBy(candidates, request, func(a, b *ProviderCandidate) bool {
+		return utilization(a.Statistics) < utilization(b.Statistics)
+	})
+}
+
+// selectBy filters out the candidates that can not fit the request and returns the first one in the given order.
+func selectBy(candidates []*ProviderCandidate, request types.ComputeResources, less func(a, b *ProviderCandidate) bool) (types.ProviderID, error) {
+	fits := make([]*ProviderCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Statistics == nil || !resourcesFit(candidate.Statistics, request) {
+			continue
+		}
+		fits = append(fits, candidate)
+	}
+
+	if len(fits) == 0 {
+		return "", ErrNoAvailableProvider
+	}
+
+	sort.SliceStable(fits, func(i, j int) bool {
+		if less(fits[i], fits[j]) {
+			return true
+		}
+		if less(fits[j], fits[i]) {
+			return false
+		}
+		return fits[i].ID < fits[j].ID
+	})
+
+	return fits[0].ID, nil
+}
+
+// resourcesFit reports whether the available resources of a provider can hold the request.
+// Memory and storage of the request are in MB, the statistics are in bytes.
+func resourcesFit(statistics *types.ResourcesStatistics, request types.ComputeResources) bool {
+	if statistics.CPUCores.Available < request.CPU {
+		return false
+	}
+	if statistics.Memory.Available < uint64(request.Memory)*1000000 {
+		return false
+	}
+	if statistics.Storage.Available < uint64(request.Storage)*1000000 {
+		return false
+	}
+	return true
+}
+
+// remainingRatio returns the average share of the provider capacity left after placing the request.
+func remainingRatio(statistics *types.ResourcesStatistics, request types.ComputeResources) float64 {
+	cpu := ratio(statistics.CPUCores.Available-request.CPU, statistics.CPUCores.MaxCPUCores)
+	memory := ratio(float64(statistics.Memory.Available)-float64(request.Memory)*1000000, float64(statistics.Memory.MaxMemory))
+	storage := ratio(float64(statistics.Storage.Available)-float64(request.Storage)*1000000, float64(statistics.Storage.MaxStorage))
+	return (cpu + memory + storage) / 3
+}
+
+// utilization returns the average share of the provider capacity already in use.
+func utilization(statistics *types.ResourcesStatistics) float64 {
+	cpu := 1 - ratio(statistics.CPUCores.Available, statistics.CPUCores.MaxCPUCores)
+	memory := 1 - ratio(float64(statistics.Memory.Available), float64(statistics.Memory.MaxMemory))
+	storage := 1 - ratio(float64(statistics.Storage.Available), float64(statistics.Storage.MaxStorage))
+	return (cpu + memory + storage) / 3
+}
+
+func ratio(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return value / total
+}
+
+// totalResources sums the compute resources requested by all replicas of the deployment services,
+// the storage includes the persistent volumes claimed by every replica.
+func totalResources(deployment *types.Deployment) types.ComputeResources {
+	return sumResources(deployment, true)
+}
+
+// scheduledResources sums the resources all replicas of the deployment services request on the nodes of the provider,
+// the persistent volumes are provisioned by the storage classes, not on the ephemeral storage of the nodes.
+func scheduledResources(deployment *types.Deployment) types.ComputeResources {
+	return sumResources(deployment, false)
+}
+
+func sumResources(deployment *types.Deployment, volumes bool) types.ComputeResources {
+	var total types.ComputeResources
+	for _, service := range deployment.Services {
+		replicas := service.Replicas
+		if replicas < 1 {
+			replicas = 1
+		}
+
+		resources := replicasResources(service, replicas)
+		if volumes {
+			for _, volume := range service.Volumes {
+				resources.Storage += volume.Size * int64(replicas)
+			}
+		}
+		total = addResources(total, resources)
+	}
+	return total
+}
+
+// replicasResources returns the resources the replicas of the service request on the nodes of the provider.
+func replicasResources(service *types.Service, replicas int) types.ComputeResources {
+	return types.ComputeResources{
+		CPU:     service.CPU * float64(replicas),
+		Memory:  service.Memory * int64(replicas),
+		Storage: service.Storage * int64(replicas),
+	}
+}
+
+// resourcesIncrease returns the resources the request needs on top of the current ones, a decreased resource needs none.
+func resourcesIncrease(current, request types.ComputeResources) types.ComputeResources {
+	var increase types.ComputeResources
+	if request.CPU > current.CPU {
+		increase.CPU = request.CPU - current.CPU
+	}
+	if request.Memory > current.Memory {
+		increase.Memory = request.Memory - current.Memory
+	}
+	if request.Storage > current.Storage {
+		increase.Storage = request.Storage - current.Storage
+	}
+	return increase
+}
+
+// checkProviderCapacity returns ErrInsufficientResources if the available resources of the provider can not hold the request.
+func (m *Manager) checkProviderCapacity(ctx context.Context, providerApi api.Provider, request types.ComputeResources) error {
+	sctx, cancel := context.WithTimeout(ctx, statisticsTimeout)
+	defer cancel()
+
+	statistics, err := providerApi.GetStatistics(sctx)
+	if err != nil {
+		return err
+	}
+
+	if !resourcesFit(statistics, request) {
+		return ErrInsufficientResources
+	}
+	return nil
+}
+
+// selectProvider picks a connected provider for the deployment with the configured selector.
+func (m *Manager) selectProvider(ctx context.Context, deployment *types.Deployment) (types.ProviderID, error) {
+	providers := m.ProviderManager.GetAll()
+	if len(providers) == 0 {
+		return "", ErrNoAvailableProvider
+	}
+
+	deploymentCounts, err := m.DB.GetDeploymentCountByProvider(ctx, types.ActiveDeploymentStates)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		lk         sync.Mutex
+		wg         sync.WaitGroup
+		candidates = make([]*ProviderCandidate, 0, len(providers))
+	)
+
+	for id, providerApi := range providers {
+		wg.Add(1)
+		go func(id types.ProviderID, providerApi api.Provider) {
+			defer wg.Done()
+
+			sctx, cancel := context.WithTimeout(ctx, statisticsTimeout)
+			defer cancel()
+
+			statistics, err := providerApi.GetStatistics(sctx)
+			if err != nil {
+				log.Warnw("failed to get provider statistics", "ProviderID", id, "error", err)
+				return
+			}
+
+			lk.Lock()
+			candidates = append(candidates, &ProviderCandidate{ID: id, Statistics: statistics, Deployments: deploymentCounts[id]})
+			lk.Unlock()
+		}(id, providerApi)
+	}
+	wg.Wait()
+
+	return m.ProviderSelector.Select(candidates, scheduledResources(deployment))
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatistics(cpu, maxCPU float64, memory, maxMemory uint64) *types.ResourcesStatistics {
+	return &types.ResourcesStatistics{
+		CPUCores: types.CPUCores{MaxCPUCores: maxCPU, Available: cpu},
+		Memory:   types.Memory{MaxMemory: maxMemory, Available: memory},
+		Storage:  types.Storage{MaxStorage: 100e9, Available: 100e9},
+	}
+}
+
+func TestProviderSelector(t *testing.T) {
+	candidates := []*ProviderCandidate{
+		{ID: "small", Statistics: newStatistics(2, 4, 2e9, 4e9), Deployments: 1},
+		{ID: "large", Statistics: newStatistics(14, 16, 30e9, 32e9), Deployments: 5},
+		{ID: "full", Statistics: newStatistics(0.1, 8, 1e8, 8e9), Deployments: 0},
+	}
+	request := types.ComputeResources{CPU: 1, Memory: 1000, Storage: 1000}
+
+	cases := []struct {
+		strategy SelectStrategy
+		expect   types.ProviderID
+	}{
+		{SelectStrategyBinPacking, "small"},
+		{SelectStrategySpread, "small"},
+		{SelectStrategyLeastLoaded, "large"},
+		{"", "large"},
+	}
+
+	for _, c := range cases {
+		selector, err := NewProviderSelectorByStrategy(c.strategy)
+		require.NoError(t, err)
+
+		id, err := selector.Select(candidates, request)
+		require.NoError(t, err)
+		require.Equal(t, c.expect, id, "strategy %q", c.strategy)
+	}
+}
+
+func TestProviderSelectorNoFit(t *testing.T) {
+	selector, err := NewProviderSelectorByStrategy(SelectStrategyLeastLoaded)
+	require.NoError(t, err)
+
+	candidates := []*ProviderCandidate{{ID: "full", Statistics: newStatistics(0.1, 8, 1e8, 8e9)}}
+	_, err = selector.Select(candidates, types.ComputeResources{CPU: 1})
+	require.ErrorIs(t, err, ErrNoAvailableProvider)
+
+	_, err = NewProviderSelectorByStrategy("random")
+	require.ErrorIs(t, err, ErrUnknownStrategy)
+}
+
+func TestTotalResources(t *testing.T) {
+	deployment := &types.Deployment{Services: []*types.Service{
+		{
+			ComputeResources: types.ComputeResources{CPU: 0.5, Memory: 512, Storage: 100},
+			Replicas:         2,
+			Volumes:          types.Volumes{{Name: "data", Size: 1000}, {Name: "logs", Size: 200}},
+		},
+		{ComputeResources: types.ComputeResources{CPU: 1, Memory: 256, Storage: 50}},
+	}}
+
+	require.Equal(t, types.ComputeResources{CPU: 2, Memory: 1280, Storage: 2650}, totalResources(deployment))
+	require.Equal(t, types.ComputeResources{CPU: 2, Memory: 1280, Storage: 250}, scheduledResources(deployment))
+}
+
+func TestResourcesIncrease(t *testing.T) {
+	current := types.ComputeResources{CPU: 2, Memory: 1024, Storage: 500}
+
+	require.Equal(t, types.ComputeResources{CPU: 1, Storage: 100}, resourcesIncrease(current, types.ComputeResources{CPU: 3, Memory: 512, Storage: 600}))
+	require.Equal(t, types.ComputeResources{}, resourcesIncrease(current, types.ComputeResources{CPU: 1, Memory: 1024}))
+}
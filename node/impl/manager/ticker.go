@@ -0,0 +1,40 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// runEvery calls tick every interval while the node runs, the stop of the node cancels the context
+// of tick and waits for it to return.
+func runEvery(lc fx.Lifecycle, interval time.Duration, tick func(ctx context.Context, now time.Time)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case now := <-ticker.C:
+						tick(ctx, now)
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/db"
+	"github.com/gnasnik/titan-container/node/config"
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
+)
+
+// UsageRecorder samples the resources allocated to the billable deployments and the resources they use on their provider
+// every interval, and stores them as usage records.
+type UsageRecorder struct {
+	interval time.Duration
+	db       *db.ManagerDB
+	pm       *ProviderManager
+}
+
+// NewUsageRecorder creates the usage recorder of the manager, no usage is recorded if it is disabled.
+func NewUsageRecorder(lc fx.Lifecycle, cfg *config.ManagerCfg, db *db.ManagerDB, pm *ProviderManager) *UsageRecorder {
+	u := &UsageRecorder{
+		interval: time.Duration(cfg.Usage.Interval),
+		db:       db,
+		pm:       pm,
+	}
+
+	if !cfg.Usage.Enabled || u.interval <= 0 {
+		return u
+	}
+
+	last := time.Now()
+	runEvery(lc, u.interval, func(ctx context.Context, now time.Time) {
+		u.sample(ctx, last, now)
+		last = now
+	})
+
+	return u
+}
+
+// sample records the usage of the billable deployments between start and end.
+func (u *UsageRecorder) sample(ctx context.Context, start, end time.Time) {
+	deployments, err := u.db.GetDeploymentsByState(ctx, types.ActiveDeploymentStates)
+	if err != nil {
+		log.Errorf("usage: get active deployments: %v", err)
+		return
+	}
+
+	records := make([]*types.UsageRecord, 0, len(deployments))
+	for _, deployment := range deployments {
+		if !billableStates[deployment.State] {
+			continue
+		}
+		records = append(records, usageRecord(deployment, u.used(ctx, deployment), start, end))
+	}
+
+	if err := u.db.AddUsageRecords(ctx, records); err != nil {
+		log.Errorf("usage: add usage records: %v", err)
+	}
+}
+
+// used returns the resources the deployment currently uses on its provider, nothing if the provider can not report them.
+func (u *UsageRecorder) used(ctx context.Context, deployment *types.Deployment) types.ResourceUsage {
+	var used types.ResourceUsage
+
+	providerApi, err := u.pm.Get(deployment.ProviderID)
+	if err != nil {
+		return used
+	}
+
+	mctx, cancel := context.WithTimeout(ctx, statisticsTimeout)
+	defer cancel()
+
+	metrics, err := providerApi.GetDeploymentMetrics(mctx, deployment.ID)
+	if err != nil {
+		log.Debugf("usage: get metrics of deployment %s: %v", deployment.ID, err)
+		return used
+	}
+
+	for _, service := range metrics.Services {
+		used.CPU += service.Usage.CPU
+		used.Memory += service.Usage.Memory
+	}
+	return used
+}
+
+// usageRecord returns the usage of the deployment between start and end, the used resources are sampled at end.
+func usageRecord(deployment *types.Deployment, used types.ResourceUsage, start, end time.Time) *types.UsageRecord {
+	seconds := end.Sub(start).Seconds()
+	allocated := totalResources(deployment)
+
+	return &types.UsageRecord{
+		DeploymentID:          deployment.ID,
+		Owner:                 deployment.Owner,
+		ProviderID:            deployment.ProviderID,
+		StartTime:             start,
+		EndTime:               end,
+		CPUSeconds:            allocated.CPU * seconds,
+		MemoryByteSeconds:     float64(allocated.Memory) * 1000000 * seconds,
+		StorageByteSeconds:    float64(allocated.Storage) * 1000000 * seconds,
+		UsedCPUSeconds:        used.CPU * seconds,
+		UsedMemoryByteSeconds: float64(used.Memory) * 1000000 * seconds,
+	}
+}
+
+// GetUsage returns the usage of the deployments of the owner by day and deployment between from and to,
+// a token scoped to some deployments of the owner only gets their usage.
+func (m *Manager) GetUsage(ctx context.Context, owner string, from, to time.Time) ([]*types.Usage, error) {
+	if !to.After(from) {
+		return nil, errors.Errorf("the end of the period %s must be after its start %s", to, from)
+	}
+
+	usage, err := m.DB.GetUsage(ctx, owner, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := api.GetAuthScope(ctx)
+	if scope == nil || len(scope.Deployments) == 0 {
+		return usage, nil
+	}
+
+	allowed := make(map[types.DeploymentID]struct{}, len(scope.Deployments))
+	for _, id := range scope.Deployments {
+		allowed[id] = struct{}{}
+	}
+
+	out := make([]*types.Usage, 0, len(usage))
+	for _, u := range usage {
+		if _, ok := allowed[u.DeploymentID]; ok {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
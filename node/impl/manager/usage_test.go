@@ -0,0 +1,31 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRecord(t *testing.T) {
+	deployment := &types.Deployment{
+		ID:         "1",
+		Owner:      "alice",
+		ProviderID: "p1",
+		Services: []*types.Service{
+			{ComputeResources: types.ComputeResources{CPU: 1, Memory: 2000, Storage: 10000}, Replicas: 2},
+		},
+	}
+
+	end := time.Now()
+	record := usageRecord(deployment, types.ResourceUsage{CPU: 0.5, Memory: 1000}, end.Add(-time.Minute), end)
+
+	require.Equal(t, "alice", record.Owner)
+	require.Equal(t, types.ProviderID("p1"), record.ProviderID)
+	require.InDelta(t, 2*60, record.CPUSeconds, 1e-9)
+	require.InDelta(t, 4000*1e6*60, record.MemoryByteSeconds, 1e-3)
+	require.InDelta(t, 20000*1e6*60, record.StorageByteSeconds, 1e-3)
+	require.InDelta(t, 0.5*60, record.UsedCPUSeconds, 1e-9)
+	require.InDelta(t, 1000*1e6*60, record.UsedMemoryByteSeconds, 1e-3)
+}
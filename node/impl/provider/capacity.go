@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// podRequest is the resources requested by a single pod of a service
+type podRequest struct {
+	service  string
+	requests corev1.ResourceList
+}
+
+// nodeFree is the resources of a node not requested by its pods yet
+type nodeFree struct {
+	name string
+	free corev1.ResourceList
+}
+
+// admissionResources are the resources checked before a deployment is applied, with their names in the errors
+var admissionResources = []struct {
+	name     corev1.ResourceName
+	resource string
+}{
+	{corev1.ResourceCPU, "cpu"},
+	{corev1.ResourceMemory, "memory"},
+	{corev1.ResourceEphemeralStorage, "storage"},
+}
+
+// CheckCapacity returns an *api.ErrInsufficientCapacity if the deployment could not be scheduled on the nodes of the cluster,
+// the manager calls it before it records a new deployment
+func (m *manager) CheckCapacity(ctx context.Context, deployment *types.Deployment) error {
+	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment, m.ingressEnabled(deployment))
+	if err != nil {
+		return err
+	}
+
+	return m.checkCapacity(ctx, k8sDeployment)
+}
+
+// checkCapacity returns an *api.ErrInsufficientCapacity if a pod of the deployment does not fit on any node of the cluster,
+// rather than leaving it pending once applied
+func (m *manager) checkCapacity(ctx context.Context, deployment builder.IClusterDeployment) error {
+	nodeResources, err := m.kc.FetchNodeResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]nodeFree, 0, len(nodeResources))
+	for name, node := range nodeResources {
+		nodes = append(nodes, nodeFree{name: name, free: node.Free()})
+	}
+
+	var pods []podRequest
+	for i, service := range deployment.ManifestGroup().Services {
+		workload := builder.NewWorkload(m.settings, deployment, i)
+		requests := workload.Requests()
+		for j := int32(0); j < service.Count; j++ {
+			pods = append(pods, podRequest{service: service.Name, requests: requests})
+		}
+	}
+
+	return admit(deployment.DeploymentID().ID, pods, nodes)
+}
+
+// admit places the pods on the nodes first fit decreasing, the way the scheduler would place them at best,
+// and returns an *api.ErrInsufficientCapacity for the first pod left without a node
+func admit(deploymentID string, pods []podRequest, nodes []nodeFree) error {
+	pods = append([]podRequest(nil), pods...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		if c := pods[i].requests.Cpu().Cmp(*pods[j].requests.Cpu()); c != 0 {
+			return c > 0
+		}
+		return pods[i].requests.Memory().Cmp(*pods[j].requests.Memory()) > 0
+	})
+
+	nodes = append([]nodeFree(nil), nodes...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+	free := make([]corev1.ResourceList, len(nodes))
+	for i := range nodes {
+		free[i] = nodes[i].free.DeepCopy()
+	}
+
+	for _, pod := range pods {
+		placed := false
+		for _, rl := range free {
+			if !fits(pod.requests, rl) {
+				continue
+			}
+			for _, r := range admissionResources {
+				if quantity, ok := pod.requests[r.name]; ok {
+					left := rl[r.name]
+					left.Sub(quantity)
+					rl[r.name] = left
+				}
+			}
+			placed = true
+			break
+		}
+
+		if !placed {
+			return insufficientCapacity(deploymentID, pod, free)
+		}
+	}
+
+	return nil
+}
+
+func fits(requests, free corev1.ResourceList) bool {
+	for _, r := range admissionResources {
+		quantity, ok := requests[r.name]
+		if !ok {
+			continue
+		}
+		left := free[r.name]
+		if quantity.Cmp(left) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// insufficientCapacity reports the first resource of the pod that exceeds what is free on every node
+func insufficientCapacity(deploymentID string, pod podRequest, free []corev1.ResourceList) error {
+	err := &api.ErrInsufficientCapacity{Deployment: deploymentID, Service: pod.service}
+	for _, r := range admissionResources {
+		quantity, ok := pod.requests[r.name]
+		if !ok {
+			continue
+		}
+
+		var most resource.Quantity
+		for _, rl := range free {
+			if left := rl[r.name]; left.Cmp(most) > 0 {
+				most = left
+			}
+		}
+
+		if quantity.Cmp(most) > 0 {
+			err.Resource = r.resource
+			err.Requested, err.Available = admissionValue(r.name, quantity), admissionValue(r.name, most)
+			break
+		}
+	}
+	return err
+}
+
+// admissionValue returns the quantity in the units of the compute resources, the cpu in cores and the memory and storage in MB
+func admissionValue(name corev1.ResourceName, quantity resource.Quantity) float64 {
+	if name == corev1.ResourceCPU {
+		return quantity.AsApproximateFloat64()
+	}
+	return float64(quantity.Value()) / 1000000
+}
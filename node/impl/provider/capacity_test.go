@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAdmit(t *testing.T) {
+	resources := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+	}
+	pod := func(service, cpu, memory string) podRequest {
+		return podRequest{service: service, requests: resources(cpu, memory)}
+	}
+	nodes := []nodeFree{{name: "node-1", free: resources("2", "4G")}, {name: "node-2", free: resources("1", "8G")}}
+
+	// three pods fit on two nodes if the largest is placed first
+	require.NoError(t, admit("d1", []podRequest{pod("web", "1", "1G"), pod("web", "1", "1G"), pod("db", "1", "6G")}, nodes))
+
+	// the free resources of a node are split between the pods
+	err := admit("d1", []podRequest{pod("web", "1500m", "1G"), pod("web", "1500m", "1G")}, nodes)
+	var capacityErr *api.ErrInsufficientCapacity
+	require.ErrorAs(t, err, &capacityErr)
+	require.Equal(t, &api.ErrInsufficientCapacity{Deployment: "d1", Service: "web", Resource: "cpu", Requested: 1.5, Available: 1}, capacityErr)
+
+	// a pod with every resource free on some node but no node with all of them
+	err = admit("d1", []podRequest{pod("db", "2", "8G")}, nodes)
+	require.ErrorAs(t, err, &capacityErr)
+	require.Equal(t, &api.ErrInsufficientCapacity{Deployment: "d1", Service: "db"}, capacityErr)
+
+	// the free resources of the nodes are not changed
+	require.True(t, nodes[0].free.Cpu().Equal(resource.MustParse("2")))
+}
@@ -12,11 +12,12 @@ import (
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
 )
 
 func TestCreateDeploy(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	port := types.Port{Port: 6379}
@@ -33,7 +34,7 @@ func TestCreateDeploy(t *testing.T) {
 
 func TestUplodateDeploy(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	port := types.Port{Port: 6379}
@@ -67,7 +68,7 @@ func TestDeleteDeploy(t *testing.T) {
 
 func TestResourcesStatistics(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	statistics, err := manager.GetStatistics(context.Background())
@@ -79,7 +80,7 @@ func TestResourcesStatistics(t *testing.T) {
 
 func TestGetDeployment(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	deployment, err := manager.GetDeployment(context.Background(), types.DeploymentID("2222"))
@@ -113,7 +114,7 @@ func TestListDeployment(t *testing.T) {
 
 func TestGetLogs(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	logs, err := manager.GetLogs(context.Background(), types.DeploymentID("1111"))
@@ -137,7 +138,7 @@ func formatLogs(log string) []string {
 
 func TestGetEvents(t *testing.T) {
 	config := &config.ProviderCfg{KubeConfigPath: "./test/config", PublicIP: "192.168.0.132"}
-	manager, err := NewManager(config)
+	manager, err := NewManager(fxtest.NewLifecycle(t), config)
 	require.NoError(t, err)
 
 	events, err := manager.GetEvents(context.Background(), types.DeploymentID("2222"))
@@ -146,7 +147,7 @@ func TestGetEvents(t *testing.T) {
 	for _, serviceEvent := range events {
 		t.Logf("event len:%d", len(serviceEvent.Events))
 		for _, event := range serviceEvent.Events {
-			t.Logf("event:%s", string(event))
+			t.Logf("event:%s", event.Message)
 		}
 	}
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/gnasnik/titan-container/api/types"
@@ -10,19 +11,24 @@ import (
 	"github.com/google/uuid"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	podReplicas = 1
+	httpPort    = 80
 )
 
-func ClusterDeploymentFromDeployment(deployment *types.Deployment) (builder.IClusterDeployment, error) {
+// ClusterDeploymentFromDeployment converts the deployment to the cluster deployment, the http ports
+// of the services are routed through an ingress if ingress is true
+func ClusterDeploymentFromDeployment(deployment *types.Deployment, ingress bool) (builder.IClusterDeployment, error) {
 	if len(deployment.ID) == 0 {
 		return nil, fmt.Errorf("deployment ID can not empty")
 	}
 
 	deploymentID := manifest.DeploymentID{ID: string(deployment.ID), Owner: deployment.Owner}
-	group, err := deploymentToManifestGroup(deployment)
+	group, err := deploymentToManifestGroup(deployment, ingress)
 	if err != nil {
 		return nil, err
 	}
@@ -38,34 +44,84 @@ func ClusterDeploymentFromDeployment(deployment *types.Deployment) (builder.IClu
 	}, nil
 }
 
-func deploymentToManifestGroup(deployment *types.Deployment) (*manifest.Group, error) {
+func deploymentToManifestGroup(deployment *types.Deployment, ingress bool) (*manifest.Group, error) {
 	if len(deployment.Services) == 0 {
 		return nil, fmt.Errorf("deployment service can not empty")
 	}
 
+	names := make(map[string]struct{}, len(deployment.Services))
 	services := make([]manifest.Service, 0, len(deployment.Services))
 	for _, service := range deployment.Services {
-		s, err := serviceToManifestService(service, deployment.Authority)
+		s, err := serviceToManifestService(service, deployment.Authority, ingress)
 		if err != nil {
 			return nil, err
 		}
+
+		if _, ok := names[s.Name]; ok {
+			return nil, fmt.Errorf("duplicate service name %s", s.Name)
+		}
+		names[s.Name] = struct{}{}
+
 		services = append(services, s)
 	}
 
-	return &manifest.Group{Services: services}, nil
+	credentials, err := registryCredentialsToManifest(deployment.RegistryCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest.Group{Services: services, RegistryCredentials: credentials}, nil
+}
+
+func registryCredentialsToManifest(credentials []*types.RegistryCredential) ([]manifest.RegistryCredential, error) {
+	servers := make(map[string]struct{}, len(credentials))
+	out := make([]manifest.RegistryCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		if len(credential.Server) == 0 || len(credential.Username) == 0 {
+			return nil, fmt.Errorf("registry credential server and username can not empty")
+		}
+
+		if _, ok := servers[credential.Server]; ok {
+			return nil, fmt.Errorf("duplicate registry credential for server %s", credential.Server)
+		}
+		servers[credential.Server] = struct{}{}
+
+		out = append(out, manifest.RegistryCredential{
+			Server:   credential.Server,
+			Username: credential.Username,
+			Password: credential.Password,
+		})
+	}
+	return out, nil
 }
 
-func serviceToManifestService(service *types.Service, Authority bool) (manifest.Service, error) {
+func serviceToManifestService(service *types.Service, Authority bool, ingress bool) (manifest.Service, error) {
 	if len(service.Image) == 0 {
 		return manifest.Service{}, fmt.Errorf("service image can not empty")
 	}
-	name := imageToServiceName(service.Image)
+	name := service.Name
+	if len(name) == 0 {
+		name = imageToServiceName(service.Image)
+	}
+
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return manifest.Service{}, fmt.Errorf("invalid service name %s: %s", name, strings.Join(errs, ","))
+	}
 	resource := resourceToManifestResource(&service.ComputeResources)
-	exposes, err := exposesFromPorts(service.Ports)
+	exposes, err := exposesFromPorts(service.Ports, ingress)
 	if err != nil {
 		return manifest.Service{}, err
 	}
 
+	if service.Replicas < 0 {
+		return manifest.Service{}, fmt.Errorf("service replicas can not be negative")
+	}
+
+	replicas := service.Replicas
+	if replicas == 0 {
+		replicas = podReplicas
+	}
+
 	s := manifest.Service{
 		Name:      name,
 		Image:     service.Image,
@@ -73,16 +129,130 @@ func serviceToManifestService(service *types.Service, Authority bool) (manifest.
 		Env:       envToManifestEnv(service.Env),
 		Resources: &resource,
 		Expose:    make([]*manifest.ServiceExpose, 0),
-		Count:     podReplicas,
+		Count:     int32(replicas),
 	}
 
 	if len(exposes) > 0 {
 		s.Expose = append(s.Expose, exposes...)
 	}
 
+	if err := addVolumesToManifestService(&s, service.Volumes); err != nil {
+		return manifest.Service{}, err
+	}
+
+	if err := addConfigToManifestService(&s, service.Secrets, service.ConfigFiles); err != nil {
+		return manifest.Service{}, err
+	}
+
+	s.Probes, err = probesToManifestProbes(service.Probes)
+	if err != nil {
+		return manifest.Service{}, err
+	}
+
 	return s, nil
 }
 
+// addConfigToManifestService adds the secrets and config files to the service, they are materialized
+// as a secret and a config map exposed to the containers as environment variables or files
+func addConfigToManifestService(s *manifest.Service, secrets []types.Secret, configFiles []types.ConfigFile) error {
+	for _, secret := range secrets {
+		data := manifest.ServiceData{Key: secret.Name, Value: secret.Value, Env: secret.Env, MountPath: secret.MountPath}
+		s.Secrets = append(s.Secrets, data)
+	}
+
+	for _, configFile := range configFiles {
+		data := manifest.ServiceData{Key: configFile.Name, Value: configFile.Content, Env: configFile.Env, MountPath: configFile.MountPath}
+		s.ConfigFiles = append(s.ConfigFiles, data)
+	}
+
+	if err := validateServiceData("secret", s.Secrets); err != nil {
+		return err
+	}
+	return validateServiceData("config file", s.ConfigFiles)
+}
+
+func validateServiceData(kind string, items []manifest.ServiceData) error {
+	keys := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if errs := validation.IsConfigMapKey(item.Key); len(errs) > 0 {
+			return fmt.Errorf("invalid %s name %s: %s", kind, item.Key, strings.Join(errs, ","))
+		}
+
+		if _, ok := keys[item.Key]; ok {
+			return fmt.Errorf("duplicate %s name %s", kind, item.Key)
+		}
+		keys[item.Key] = struct{}{}
+
+		if len(item.Env) == 0 && len(item.MountPath) == 0 {
+			return fmt.Errorf("%s %s must set an env or a mount path", kind, item.Key)
+		}
+
+		if len(item.Env) > 0 {
+			if errs := validation.IsEnvVarName(item.Env); len(errs) > 0 {
+				return fmt.Errorf("invalid %s %s env %s: %s", kind, item.Key, item.Env, strings.Join(errs, ","))
+			}
+		}
+
+		if len(item.MountPath) > 0 && !path.IsAbs(item.MountPath) {
+			return fmt.Errorf("%s %s mount path must be absolute", kind, item.Key)
+		}
+	}
+
+	return nil
+}
+
+// addVolumesToManifestService adds the volumes as persistent storage of the service, a service
+// with persistent storage is deployed as a statefulset
+func addVolumesToManifestService(s *manifest.Service, volumes types.Volumes) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	s.Params = &manifest.ServiceParams{Storage: make([]manifest.StorageParams, 0, len(volumes))}
+
+	names := make(map[string]struct{}, len(volumes))
+	for _, volume := range volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid volume name %s: %s", volume.Name, strings.Join(errs, ","))
+		}
+
+		if _, ok := names[volume.Name]; ok {
+			return fmt.Errorf("duplicate volume name %s", volume.Name)
+		}
+		names[volume.Name] = struct{}{}
+
+		if volume.Size <= 0 {
+			return fmt.Errorf("volume %s size must be greater than 0", volume.Name)
+		}
+
+		if !path.IsAbs(volume.MountPath) {
+			return fmt.Errorf("volume %s mount path must be absolute", volume.Name)
+		}
+
+		storageClass := volume.StorageClass
+		if len(storageClass) == 0 {
+			storageClass = builder.StorageClassDefault
+		}
+
+		s.Resources.Storage = append(s.Resources.Storage, &manifest.Storage{
+			Name:     volume.Name,
+			Quantity: manifest.NewResourceValue(uint64(volume.Size * 1000000)),
+			Attributes: manifest.Attributes{
+				{Key: builder.StorageAttributePersistent, Value: "true"},
+				{Key: builder.StorageAttributeClass, Value: storageClass},
+			},
+		})
+
+		s.Params.Storage = append(s.Params.Storage, manifest.StorageParams{
+			Name:     volume.Name,
+			Mount:    volume.MountPath,
+			ReadOnly: volume.ReadOnly,
+		})
+	}
+
+	return nil
+}
+
 func envToManifestEnv(serviceEnv types.Env) []string {
 	envs := make([]string, 0, len(serviceEnv))
 	for k, v := range serviceEnv {
@@ -92,6 +262,8 @@ func envToManifestEnv(serviceEnv types.Env) []string {
 	return envs
 }
 
+// imageToServiceName generates a name for a service that has not been named yet,
+// the name is persisted by the manager so the service keeps it across updates.
 func imageToServiceName(image string) string {
 	names := strings.Split(image, "/")
 	names = strings.Split(names[len(names)-1], ":")
@@ -120,7 +292,9 @@ func serviceProto(protocol types.Protocol) (manifest.ServiceProtocol, error) {
 	return serviceProto, nil
 }
 
-func exposesFromPorts(ports types.Ports) ([]*manifest.ServiceExpose, error) {
+// exposesFromPorts converts the ports to exposes, if ingress is true the tcp ports which are 80
+// or have hosts are exposed on port 80 of the service and routed through the ingress
+func exposesFromPorts(ports types.Ports, ingress bool) ([]*manifest.ServiceExpose, error) {
 	if len(ports) == 0 {
 		return nil, nil
 	}
@@ -132,6 +306,18 @@ func exposesFromPorts(ports types.Ports) ([]*manifest.ServiceExpose, error) {
 			return nil, err
 		}
 		serviceExpose := &manifest.ServiceExpose{Port: uint32(port.Port), ExternalPort: uint32(port.Port), Proto: proto, Global: true}
+
+		if ingress && proto == manifest.TCP && (port.Port == httpPort || len(port.Hosts) > 0) {
+			for _, host := range port.Hosts {
+				if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+					return nil, fmt.Errorf("invalid host %s: %s", host, strings.Join(errs, ","))
+				}
+			}
+
+			serviceExpose.ExternalPort = httpPort
+			serviceExpose.Hosts = port.Hosts
+		}
+
 		serviceExposes = append(serviceExposes, serviceExpose)
 	}
 	return serviceExposes, nil
@@ -157,7 +343,10 @@ func k8sDeploymentToService(deployment *appsv1.Deployment) (*types.Service, erro
 	}
 
 	container := deployment.Spec.Template.Spec.Containers[0]
-	service := &types.Service{Image: container.Image, Name: container.Name}
+	service := &types.Service{Image: container.Image, Name: container.Name, Replicas: podReplicas}
+	if deployment.Spec.Replicas != nil {
+		service.Replicas = int(*deployment.Spec.Replicas)
+	}
 	service.CPU = container.Resources.Limits.Cpu().AsApproximateFloat64()
 	service.Memory = container.Resources.Limits.Memory().Value() / 1000000
 	service.Storage = int64(container.Resources.Limits.StorageEphemeral().AsApproximateFloat64()) / 1000000
@@ -172,6 +361,67 @@ func k8sDeploymentToService(deployment *appsv1.Deployment) (*types.Service, erro
 	return service, nil
 }
 
+func k8sStatefulSetsToServices(statefulSetList *appsv1.StatefulSetList) ([]*types.Service, error) {
+	services := make([]*types.Service, 0, len(statefulSetList.Items))
+
+	for _, statefulSet := range statefulSetList.Items {
+		s, err := k8sStatefulSetToService(&statefulSet)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, s)
+	}
+
+	return services, nil
+}
+
+func k8sStatefulSetToService(statefulSet *appsv1.StatefulSet) (*types.Service, error) {
+	if len(statefulSet.Spec.Template.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("statefulset container can not empty")
+	}
+
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	service := &types.Service{Image: container.Image, Name: container.Name, Replicas: podReplicas}
+	service.CPU = container.Resources.Limits.Cpu().AsApproximateFloat64()
+	service.Memory = container.Resources.Limits.Memory().Value() / 1000000
+	service.Storage = int64(container.Resources.Limits.StorageEphemeral().AsApproximateFloat64()) / 1000000
+
+	if statefulSet.Spec.Replicas != nil {
+		service.Replicas = int(*statefulSet.Spec.Replicas)
+	}
+
+	mounts := make(map[string]corev1.VolumeMount, len(container.VolumeMounts))
+	for _, mount := range container.VolumeMounts {
+		mounts[mount.Name] = mount
+	}
+
+	for _, pvc := range statefulSet.Spec.VolumeClaimTemplates {
+		volume := types.Volume{
+			Name: strings.TrimPrefix(pvc.Name, container.Name+"-"),
+			Size: pvc.Spec.Resources.Requests.Storage().Value() / 1000000,
+		}
+
+		if pvc.Spec.StorageClassName != nil {
+			volume.StorageClass = *pvc.Spec.StorageClassName
+		}
+
+		if mount, ok := mounts[pvc.Name]; ok {
+			volume.MountPath = mount.MountPath
+			volume.ReadOnly = mount.ReadOnly
+		}
+
+		service.Volumes = append(service.Volumes, volume)
+	}
+
+	service.Status = types.ReplicasStatus{
+		TotalReplicas:     int(statefulSet.Status.Replicas),
+		ReadyReplicas:     int(statefulSet.Status.ReadyReplicas),
+		AvailableReplicas: int(statefulSet.Status.AvailableReplicas),
+	}
+
+	return service, nil
+}
+
 func k8sServiceToPortMap(serviceList *corev1.ServiceList) (map[string]types.Ports, error) {
 	portMap := make(map[string]types.Ports)
 	for _, service := range serviceList.Items {
@@ -194,3 +444,18 @@ func servicePortsToPortPairs(servicePorts []corev1.ServicePort) types.Ports {
 	}
 	return types.Ports(ports)
 }
+
+func k8sIngressToURLMap(ingressList *netv1.IngressList) map[string][]string {
+	urlMap := make(map[string][]string)
+	for _, ingress := range ingressList.Items {
+		scheme := "http"
+		if len(ingress.Spec.TLS) > 0 {
+			scheme = "https"
+		}
+
+		for _, rule := range ingress.Spec.Rules {
+			urlMap[ingress.Name] = append(urlMap[ingress.Name], fmt.Sprintf("%s://%s", scheme, rule.Host))
+		}
+	}
+	return urlMap
+}
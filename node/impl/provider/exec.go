@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+const execOutputBufferSize = 64
+
+type execSession struct {
+	stdin  *io.PipeWriter
+	sizeCh chan remotecommand.TerminalSize
+	done   chan struct{}
+}
+
+// Next implements remotecommand.TerminalSizeQueue
+func (s *execSession) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-s.sizeCh:
+		return &size
+	case <-s.done:
+		return nil
+	}
+}
+
+// resize queues the size of the terminal, replacing a size not read yet. The size is dropped once the session exited.
+func (s *execSession) resize(size remotecommand.TerminalSize) {
+	for {
+		select {
+		case s.sizeCh <- size:
+			return
+		case <-s.done:
+			return
+		default:
+		}
+
+		// make room for the latest size, another resize may have taken it already
+		select {
+		case <-s.sizeCh:
+		default:
+		}
+	}
+}
+
+type execSessions struct {
+	lk       sync.Mutex
+	sessions map[string]*execSession
+}
+
+func (e *execSessions) add(id string, session *execSession) error {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	if _, ok := e.sessions[id]; ok {
+		return fmt.Errorf("exec session %s already exist", id)
+	}
+	e.sessions[id] = session
+	return nil
+}
+
+func (e *execSessions) get(id string) (*execSession, error) {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	session, ok := e.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("exec session %s do not exist", id)
+	}
+	return session, nil
+}
+
+func (e *execSessions) remove(id string) {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+	delete(e.sessions, id)
+}
+
+// execWriter sends the data written to it to the exec output channel
+type execWriter struct {
+	ctx    context.Context
+	out    chan<- *types.ExecOutput
+	stderr bool
+}
+
+func (w *execWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	output := &types.ExecOutput{Stdout: data}
+	if w.stderr {
+		output = &types.ExecOutput{Stderr: data}
+	}
+
+	select {
+	case w.out <- output:
+		return len(p), nil
+	case <-w.ctx.Done():
+		return 0, w.ctx.Err()
+	}
+}
+
+func (m *manager) ExecDeployment(ctx context.Context, id types.DeploymentID, opt *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	if opt == nil || len(opt.Command) == 0 {
+		return nil, fmt.Errorf("exec command can not empty")
+	}
+
+	if len(opt.SessionID) == 0 {
+		return nil, fmt.Errorf("exec session id can not empty")
+	}
+
+	deploymentID := manifest.DeploymentID{ID: string(id)}
+	ns := builder.DidNS(deploymentID)
+
+	podName, serviceName, err := m.getExecPod(ctx, ns, opt.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &execSession{
+		sizeCh: make(chan remotecommand.TerminalSize, 1),
+		done:   make(chan struct{}),
+	}
+
+	if opt.Size != nil {
+		session.sizeCh <- remotecommand.TerminalSize{Width: opt.Size.Width, Height: opt.Size.Height}
+	}
+
+	out := make(chan *types.ExecOutput, execOutputBufferSize)
+	execOpts := kube.ExecOptions{
+		Container: serviceName,
+		Command:   opt.Command,
+		Stdout:    &execWriter{ctx: ctx, out: out},
+		Stderr:    &execWriter{ctx: ctx, out: out, stderr: true},
+		TTY:       opt.TTY,
+	}
+
+	var stdin *io.PipeReader
+	if opt.Stdin {
+		stdin, session.stdin = io.Pipe()
+		execOpts.Stdin = stdin
+	}
+
+	if opt.TTY {
+		execOpts.TerminalSizeQueue = session
+	}
+
+	if err := m.execSessions.add(opt.SessionID, session); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(out)
+		defer m.execSessions.remove(opt.SessionID)
+		defer close(session.done)
+
+		err := m.kc.Exec(ctx, ns, podName, execOpts)
+		if stdin != nil {
+			stdin.Close()
+		}
+
+		result := &types.ExecOutput{Exited: true}
+		if err != nil {
+			if exitErr, ok := err.(exec.ExitError); ok {
+				result.ExitCode = exitErr.ExitStatus()
+			} else {
+				result.ExitCode = -1
+				result.Error = err.Error()
+			}
+		}
+
+		select {
+		case out <- result:
+		case <-ctx.Done():
+		}
+	}()
+
+	return out, nil
+}
+
+func (m *manager) ExecInput(ctx context.Context, input *types.ExecInput) error {
+	session, err := m.execSessions.get(input.SessionID)
+	if err != nil {
+		return err
+	}
+
+	if input.Resize != nil {
+		session.resize(remotecommand.TerminalSize{Width: input.Resize.Width, Height: input.Resize.Height})
+	}
+
+	if session.stdin == nil {
+		if len(input.Data) > 0 {
+			return fmt.Errorf("exec session %s stdin is not attached", input.SessionID)
+		}
+		return nil
+	}
+
+	if len(input.Data) > 0 {
+		if _, err := session.stdin.Write(input.Data); err != nil {
+			return err
+		}
+	}
+
+	if input.CloseStdin {
+		return session.stdin.Close()
+	}
+
+	return nil
+}
+
+// getExecPod returns a pod of the service and the service name, the service name can
+// be empty if there is only one service in the namespace
+func (m *manager) getExecPod(ctx context.Context, ns string, serviceName string) (string, string, error) {
+	pods, err := m.getPods(ctx, ns)
+	if err != nil {
+		return "", "", err
+	}
+
+	podNames := make([]string, 0, len(pods))
+	services := make(map[string]struct{})
+	for podName, service := range pods {
+		if len(serviceName) > 0 && service != serviceName {
+			continue
+		}
+		podNames = append(podNames, podName)
+		services[service] = struct{}{}
+	}
+
+	if len(podNames) == 0 {
+		return "", "", fmt.Errorf("can not find pod of service %q in namespace %s", serviceName, ns)
+	}
+
+	if len(services) > 1 {
+		return "", "", fmt.Errorf("deployment has multiple services, service name is required")
+	}
+
+	sort.Strings(podNames)
+	return podNames[0], pods[podNames[0]], nil
+}
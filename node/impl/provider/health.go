@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// eventReasonUnhealthy is the reason of the events kubelet reports for failed probes
+const eventReasonUnhealthy = "Unhealthy"
+
+// healthSeverity orders the health of the pods, the health of a service is the worst health of its pods
+var healthSeverity = map[types.ServiceHealth]int{
+	types.ServiceHealthHealthy:   0,
+	types.ServiceHealthStarting:  1,
+	types.ServiceHealthUnhealthy: 2,
+	types.ServiceHealthCrashing:  3,
+}
+
+// getServicesHealth returns the health of the services in the namespace by service name
+func (m *manager) getServicesHealth(ctx context.Context, ns string) (map[string]types.ServiceHealth, error) {
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := cache.ListPods(ns, labels.SelectorFromSet(labels.Set{builder.TitanManagedLabelName: "true"}))
+	if err != nil {
+		return nil, err
+	}
+
+	health := make(map[string]types.ServiceHealth)
+	for i := range podList.Items {
+		serviceName := podList.Items[i].Labels[builder.TitanManifestServiceLabelName]
+		if len(serviceName) == 0 {
+			continue
+		}
+
+		h := podHealth(&podList.Items[i])
+		if current, ok := health[serviceName]; !ok || healthSeverity[h] > healthSeverity[current] {
+			health[serviceName] = h
+		}
+	}
+
+	return health, nil
+}
+
+// podHealth tells a pod whose containers keep exiting from a pod whose running containers fail their probes
+func podHealth(pod *corev1.Pod) types.ServiceHealth {
+	if pod.Status.Phase == corev1.PodPending && len(pod.Status.ContainerStatuses) == 0 {
+		return types.ServiceHealthStarting
+	}
+
+	health := types.ServiceHealthHealthy
+	for _, status := range pod.Status.ContainerStatuses {
+		var h types.ServiceHealth
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff":
+			h = types.ServiceHealthCrashing
+		case status.State.Terminated != nil:
+			h = types.ServiceHealthCrashing
+		case status.State.Waiting != nil:
+			h = types.ServiceHealthStarting
+		case status.Started != nil && !*status.Started:
+			h = types.ServiceHealthStarting
+		case !status.Ready:
+			h = types.ServiceHealthUnhealthy
+		default:
+			h = types.ServiceHealthHealthy
+		}
+
+		if healthSeverity[h] > healthSeverity[health] {
+			health = h
+		}
+	}
+
+	return health
+}
+
+// eventProbe returns the kind of the probe whose failure is reported by the event
+func eventProbe(event *corev1.Event) types.ProbeKind {
+	if event.Reason != eventReasonUnhealthy {
+		return ""
+	}
+
+	message := strings.ToLower(event.Message)
+	for _, kind := range []types.ProbeKind{types.ProbeLiveness, types.ProbeReadiness, types.ProbeStartup} {
+		if strings.HasPrefix(message, string(kind)+" probe") {
+			return kind
+		}
+	}
+	return ""
+}
+
+func k8sEventToEvent(event *corev1.Event) types.Event {
+	eventTime := event.LastTimestamp.Time
+	if eventTime.IsZero() {
+		eventTime = event.EventTime.Time
+	}
+
+	return types.Event{
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: event.Message,
+		Probe:   eventProbe(event),
+		PodName: event.InvolvedObject.Name,
+		Count:   int(event.Count),
+		Time:    eventTime,
+	}
+}
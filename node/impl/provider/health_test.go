@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodHealth(t *testing.T) {
+	started, notStarted := true, false
+	cases := []struct {
+		status corev1.ContainerStatus
+		expect types.ServiceHealth
+	}{
+		{corev1.ContainerStatus{Ready: true, Started: &started, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}, types.ServiceHealthHealthy},
+		{corev1.ContainerStatus{Started: &started, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}, types.ServiceHealthUnhealthy},
+		{corev1.ContainerStatus{Started: &notStarted, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}, types.ServiceHealthStarting},
+		{corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}}, types.ServiceHealthStarting},
+		{corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}, types.ServiceHealthCrashing},
+	}
+
+	for _, c := range cases {
+		pod := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{c.status}}}
+		require.Equal(t, c.expect, podHealth(pod))
+	}
+}
+
+func TestEventProbe(t *testing.T) {
+	event := &corev1.Event{Reason: eventReasonUnhealthy, Message: "Readiness probe failed: dial tcp 10.0.0.1:80: connect: connection refused"}
+	require.Equal(t, types.ProbeReadiness, eventProbe(event))
+
+	event = &corev1.Event{Reason: "BackOff", Message: "Back-off restarting failed container"}
+	require.Equal(t, types.ProbeKind(""), eventProbe(event))
+}
+
+func TestProbeValidation(t *testing.T) {
+	_, err := probesToManifestProbes(types.Probes{Liveness: &types.Probe{}})
+	require.Error(t, err)
+
+	_, err = probesToManifestProbes(types.Probes{Liveness: &types.Probe{TCPSocket: &types.TCPSocketProbe{Port: 80}, SuccessThreshold: 2}})
+	require.Error(t, err)
+
+	probes, err := probesToManifestProbes(types.Probes{Readiness: &types.Probe{HTTPGet: &types.HTTPGetProbe{Port: 8080}}})
+	require.NoError(t, err)
+	require.Equal(t, "/", probes.Readiness.HTTPGet.Path)
+	require.Nil(t, probes.Liveness)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -116,6 +117,68 @@ func applyService(ctx context.Context, kc kubernetes.Interface, b builder.Servic
 	return err
 }
 
+// secretBuilder is implemented by the builders of the registry secret and the service secrets
+type secretBuilder interface {
+	NS() string
+	Name() string
+	Create() (*corev1.Secret, error)
+	Update(obj *corev1.Secret) (*corev1.Secret, error)
+}
+
+func applySecret(ctx context.Context, kc kubernetes.Interface, b secretBuilder) error {
+	obj, err := kc.CoreV1().Secrets(b.NS()).Get(ctx, b.Name(), metav1.GetOptions{})
+
+	switch {
+	case err == nil:
+		obj, err = b.Update(obj)
+		if err == nil {
+			_, err = kc.CoreV1().Secrets(b.NS()).Update(ctx, obj, metav1.UpdateOptions{})
+		}
+	case errors.IsNotFound(err):
+		obj, err = b.Create()
+		if err == nil {
+			_, err = kc.CoreV1().Secrets(b.NS()).Create(ctx, obj, metav1.CreateOptions{})
+		}
+	}
+	return err
+}
+
+func applyConfigMap(ctx context.Context, kc kubernetes.Interface, b builder.ServiceConfigMap) error {
+	obj, err := kc.CoreV1().ConfigMaps(b.NS()).Get(ctx, b.Name(), metav1.GetOptions{})
+
+	switch {
+	case err == nil:
+		obj, err = b.Update(obj)
+		if err == nil {
+			_, err = kc.CoreV1().ConfigMaps(b.NS()).Update(ctx, obj, metav1.UpdateOptions{})
+		}
+	case errors.IsNotFound(err):
+		obj, err = b.Create()
+		if err == nil {
+			_, err = kc.CoreV1().ConfigMaps(b.NS()).Create(ctx, obj, metav1.CreateOptions{})
+		}
+	}
+	return err
+}
+
+func applyIngress(ctx context.Context, kc kubernetes.Interface, b builder.Ingress) error {
+	obj, err := kc.NetworkingV1().Ingresses(b.NS()).Get(ctx, b.Name(), metav1.GetOptions{})
+
+	switch {
+	case err == nil:
+		obj, err = b.Update(obj)
+		if err == nil {
+			_, err = kc.NetworkingV1().Ingresses(b.NS()).Update(ctx, obj, metav1.UpdateOptions{})
+		}
+	case errors.IsNotFound(err):
+		obj, err = b.Create()
+		if err == nil {
+			_, err = kc.NetworkingV1().Ingresses(b.NS()).Create(ctx, obj, metav1.CreateOptions{})
+		}
+	}
+	return err
+}
+
 // func applyManifest(ctx context.Context, kc crdapi.Interface, b builder.Manifest) error {
 // 	obj, err := kc.AkashV2beta2().Manifests(b.NS()).Get(ctx, b.Name(), metav1.GetOptions{})
 
@@ -101,8 +101,11 @@ func DidNS(did manifest.DeploymentID) string {
 	return did.ID
 }
 
+// ingressPort is the external port of the exposes routed through the ingress
+const ingressPort = 80
+
 func shouldBeIngress(expose *manifest.ServiceExpose) bool {
-	return expose.Proto == manifest.TCP && expose.Global && exposeExternalPort(expose) == 80
+	return expose.Proto == manifest.TCP && expose.Global && exposeExternalPort(expose) == ingressPort
 }
 
 func exposeExternalPort(expose *manifest.ServiceExpose) int32 {
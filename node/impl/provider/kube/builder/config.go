@@ -0,0 +1,244 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	serviceSecretsVolume = "titan-secrets"
+	serviceConfigVolume  = "titan-config"
+
+	// configChecksumAnnotation rolls the pods of a service when its secrets or config files change
+	configChecksumAnnotation = "titan.provider/config-checksum"
+)
+
+// ServiceSecretName returns the name of the secret holding the secrets of the service
+func ServiceSecretName(service string) string {
+	return service + "-secrets"
+}
+
+// ServiceConfigMapName returns the name of the config map holding the config files of the service
+func ServiceConfigMapName(service string) string {
+	return service + "-config"
+}
+
+type ServiceSecret interface {
+	workloadBase
+	Create() (*corev1.Secret, error)
+	Update(obj *corev1.Secret) (*corev1.Secret, error)
+	Any() bool
+}
+
+type serviceSecret struct {
+	Workload
+}
+
+var _ ServiceSecret = (*serviceSecret)(nil)
+
+// BuildServiceSecret builds the secret holding the secrets of the service
+func BuildServiceSecret(workload Workload) ServiceSecret {
+	return &serviceSecret{Workload: workload}
+}
+
+func (b *serviceSecret) Name() string {
+	return ServiceSecretName(b.Workload.Name())
+}
+
+func (b *serviceSecret) Any() bool {
+	return len(b.deployment.ManifestGroup().Services[b.serviceIdx].Secrets) > 0
+}
+
+func (b *serviceSecret) Create() (*corev1.Secret, error) { // nolint:golint,unparam
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.Name(),
+			Labels: b.labels(),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: b.data(),
+	}, nil
+}
+
+func (b *serviceSecret) Update(obj *corev1.Secret) (*corev1.Secret, error) { // nolint:golint,unparam
+	obj.Labels = b.labels()
+	obj.Data = b.data()
+	obj.StringData = nil
+	return obj, nil
+}
+
+func (b *serviceSecret) data() map[string][]byte {
+	data := make(map[string][]byte)
+	for _, secret := range b.deployment.ManifestGroup().Services[b.serviceIdx].Secrets {
+		data[secret.Key] = []byte(secret.Value)
+	}
+	return data
+}
+
+type ServiceConfigMap interface {
+	workloadBase
+	Create() (*corev1.ConfigMap, error)
+	Update(obj *corev1.ConfigMap) (*corev1.ConfigMap, error)
+	Any() bool
+}
+
+type serviceConfigMap struct {
+	Workload
+}
+
+var _ ServiceConfigMap = (*serviceConfigMap)(nil)
+
+// BuildServiceConfigMap builds the config map holding the config files of the service
+func BuildServiceConfigMap(workload Workload) ServiceConfigMap {
+	return &serviceConfigMap{Workload: workload}
+}
+
+func (b *serviceConfigMap) Name() string {
+	return ServiceConfigMapName(b.Workload.Name())
+}
+
+func (b *serviceConfigMap) Any() bool {
+	return len(b.deployment.ManifestGroup().Services[b.serviceIdx].ConfigFiles) > 0
+}
+
+func (b *serviceConfigMap) Create() (*corev1.ConfigMap, error) { // nolint:golint,unparam
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.Name(),
+			Labels: b.labels(),
+		},
+		Data: b.data(),
+	}, nil
+}
+
+func (b *serviceConfigMap) Update(obj *corev1.ConfigMap) (*corev1.ConfigMap, error) { // nolint:golint,unparam
+	obj.Labels = b.labels()
+	obj.Data = b.data()
+	obj.BinaryData = nil
+	return obj, nil
+}
+
+func (b *serviceConfigMap) data() map[string]string {
+	data := make(map[string]string)
+	for _, configFile := range b.deployment.ManifestGroup().Services[b.serviceIdx].ConfigFiles {
+		data[configFile.Key] = configFile.Value
+	}
+	return data
+}
+
+// configEnv returns the environment variables referencing the secrets and config files of the service
+func (b *Workload) configEnv() []corev1.EnvVar {
+	service := &b.deployment.ManifestGroup().Services[b.serviceIdx]
+
+	var env []corev1.EnvVar
+	for _, secret := range service.Secrets {
+		if secret.Env == "" {
+			continue
+		}
+
+		env = append(env, corev1.EnvVar{
+			Name: secret.Env,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ServiceSecretName(service.Name)},
+					Key:                  secret.Key,
+				},
+			},
+		})
+	}
+
+	for _, configFile := range service.ConfigFiles {
+		if configFile.Env == "" {
+			continue
+		}
+
+		env = append(env, corev1.EnvVar{
+			Name: configFile.Env,
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ServiceConfigMapName(service.Name)},
+					Key:                  configFile.Key,
+				},
+			},
+		})
+	}
+
+	return env
+}
+
+// configVolumeMounts mounts each secret and config file with a mount path as a single file
+func (b *Workload) configVolumeMounts() []corev1.VolumeMount {
+	service := &b.deployment.ManifestGroup().Services[b.serviceIdx]
+
+	var mounts []corev1.VolumeMount
+	for _, secret := range service.Secrets {
+		if secret.MountPath != "" {
+			mounts = append(mounts, corev1.VolumeMount{Name: serviceSecretsVolume, MountPath: secret.MountPath, SubPath: secret.Key, ReadOnly: true})
+		}
+	}
+
+	for _, configFile := range service.ConfigFiles {
+		if configFile.MountPath != "" {
+			mounts = append(mounts, corev1.VolumeMount{Name: serviceConfigVolume, MountPath: configFile.MountPath, SubPath: configFile.Key, ReadOnly: true})
+		}
+	}
+
+	return mounts
+}
+
+// configVolumes returns the pod volumes of the secrets and config files mounted by the container
+func (b *Workload) configVolumes() []corev1.Volume {
+	service := &b.deployment.ManifestGroup().Services[b.serviceIdx]
+
+	mounted := make(map[string]bool)
+	for _, mount := range b.configVolumeMounts() {
+		mounted[mount.Name] = true
+	}
+
+	var volumes []corev1.Volume
+	if mounted[serviceSecretsVolume] {
+		volumes = append(volumes, corev1.Volume{
+			Name: serviceSecretsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: ServiceSecretName(service.Name)},
+			},
+		})
+	}
+
+	if mounted[serviceConfigVolume] {
+		volumes = append(volumes, corev1.Volume{
+			Name: serviceConfigVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ServiceConfigMapName(service.Name)},
+				},
+			},
+		})
+	}
+
+	return volumes
+}
+
+// podAnnotations sets the config checksum annotation of the pod template, the pods are replaced
+// when the secrets or config files of the service change
+func (b *Workload) podAnnotations(annotations map[string]string) map[string]string {
+	service := &b.deployment.ManifestGroup().Services[b.serviceIdx]
+	if len(service.Secrets) == 0 && len(service.ConfigFiles) == 0 {
+		delete(annotations, configChecksumAnnotation)
+		return annotations
+	}
+
+	buf, _ := json.Marshal([][]manifest.ServiceData{service.Secrets, service.ConfigFiles})
+	sum := sha256.Sum256(buf)
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[configChecksumAnnotation] = hex.EncodeToString(sum[:])
+	return annotations
+}
@@ -4,6 +4,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type Deployment interface {
@@ -33,13 +34,17 @@ func (b *deployment) Create() (*appsv1.Deployment, error) { // nolint:golint,unp
 				MatchLabels: b.labels(),
 			},
 			Replicas: b.replicas(),
+			Strategy: b.strategy(),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: b.labels(),
+					Labels:      b.labels(),
+					Annotations: b.podAnnotations(nil),
 				},
 				Spec: corev1.PodSpec{
+					RuntimeClassName: b.runtimeClass(),
 					Containers:       []corev1.Container{b.container()},
 					ImagePullSecrets: b.imagePullSecrets(),
+					Volumes:          b.configVolumes(),
 				},
 			},
 		},
@@ -52,9 +57,31 @@ func (b *deployment) Update(obj *appsv1.Deployment) (*appsv1.Deployment, error)
 	obj.Labels = b.labels()
 	obj.Spec.Selector.MatchLabels = b.labels()
 	obj.Spec.Replicas = b.replicas()
+	obj.Spec.Strategy = b.strategy()
 	obj.Spec.Template.Labels = b.labels()
+	obj.Spec.Template.Annotations = b.podAnnotations(obj.Spec.Template.Annotations)
 	obj.Spec.Template.Spec.Containers = []corev1.Container{b.container()}
 	obj.Spec.Template.Spec.ImagePullSecrets = b.imagePullSecrets()
+	obj.Spec.Template.Spec.RuntimeClassName = b.runtimeClass()
+	obj.Spec.Template.Spec.Volumes = b.configVolumes()
 
 	return obj, nil
 }
+
+// strategy returns the rolling update strategy configured in the settings
+func (b *deployment) strategy() appsv1.DeploymentStrategy {
+	rollingUpdate := &appsv1.RollingUpdateDeployment{}
+	if b.settings.DeploymentMaxSurge != "" {
+		maxSurge := intstr.Parse(b.settings.DeploymentMaxSurge)
+		rollingUpdate.MaxSurge = &maxSurge
+	}
+	if b.settings.DeploymentMaxUnavailable != "" {
+		maxUnavailable := intstr.Parse(b.settings.DeploymentMaxUnavailable)
+		rollingUpdate.MaxUnavailable = &maxUnavailable
+	}
+
+	return appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: rollingUpdate,
+	}
+}
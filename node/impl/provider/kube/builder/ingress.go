@@ -0,0 +1,169 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Ingress interface {
+	workloadBase
+	Create() (*netv1.Ingress, error)
+	Update(obj *netv1.Ingress) (*netv1.Ingress, error)
+	Hosts() []string
+	GeneratedHost() string
+	UnderIngressDomain(host string) bool
+	Any() bool
+}
+
+type ingress struct {
+	Workload
+}
+
+var _ Ingress = (*ingress)(nil)
+
+// BuildIngress routes the http exposes of the service to the service of the workload
+func BuildIngress(workload Workload) Ingress {
+	return &ingress{Workload: workload}
+}
+
+// Hosts returns the hosts routed to the service, the custom hosts of the exposes and
+// the generated host if static hosts are enabled in the settings.
+// The custom hosts under the ingress domain other than the generated host are left out,
+// they belong to the other deployments.
+func (b *ingress) Hosts() []string {
+	if !b.hasIngressExpose() {
+		return nil
+	}
+
+	service := &b.deployment.ManifestGroup().Services[b.serviceIdx]
+
+	var hosts []string
+	added := make(map[string]struct{})
+	for _, expose := range service.Expose {
+		if !shouldBeIngress(expose) {
+			continue
+		}
+
+		for _, host := range expose.Hosts {
+			if b.UnderIngressDomain(host) && host != b.GeneratedHost() {
+				continue
+			}
+			if _, ok := added[host]; !ok {
+				added[host] = struct{}{}
+				hosts = append(hosts, host)
+			}
+		}
+	}
+
+	if b.settings.DeploymentIngressStaticHosts {
+		host := b.GeneratedHost()
+		if _, ok := added[host]; !ok {
+			hosts = append([]string{host}, hosts...)
+		}
+	}
+
+	return hosts
+}
+
+// GeneratedHost returns the host of the service under the ingress domain,
+// the first service with an http expose gets <deployment>.<domain>,
+// the other services get <service>-<deployment>.<domain>.
+func (b *ingress) GeneratedHost() string {
+	if b.serviceIdx != firstIngressService(b.deployment) {
+		return fmt.Sprintf("%s-%s.%s", b.Name(), b.NS(), b.settings.DeploymentIngressDomain)
+	}
+	return fmt.Sprintf("%s.%s", b.NS(), b.settings.DeploymentIngressDomain)
+}
+
+// UnderIngressDomain reports whether the host is the ingress domain of the settings or one of its subdomains
+func (b *ingress) UnderIngressDomain(host string) bool {
+	domain := b.settings.DeploymentIngressDomain
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+func (b *ingress) Any() bool {
+	return len(b.Hosts()) > 0
+}
+
+func (b *ingress) hasIngressExpose() bool {
+	for _, expose := range b.deployment.ManifestGroup().Services[b.serviceIdx].Expose {
+		if shouldBeIngress(expose) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstIngressService(deployment IClusterDeployment) int {
+	for i, service := range deployment.ManifestGroup().Services {
+		for _, expose := range service.Expose {
+			if shouldBeIngress(expose) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (b *ingress) rules() []netv1.IngressRule {
+	pathType := netv1.PathTypePrefix
+
+	hosts := b.Hosts()
+	rules := make([]netv1.IngressRule, 0, len(hosts))
+	for _, host := range hosts {
+		rules = append(rules, netv1.IngressRule{
+			Host: host,
+			IngressRuleValue: netv1.IngressRuleValue{
+				HTTP: &netv1.HTTPIngressRuleValue{
+					Paths: []netv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: netv1.IngressBackend{
+							Service: &netv1.IngressServiceBackend{
+								// the local service of the workload exposes the ingress port
+								Name: b.Name(),
+								Port: netv1.ServiceBackendPort{Number: ingressPort},
+							},
+						},
+					}},
+				},
+			},
+		})
+	}
+
+	return rules
+}
+
+func (b *ingress) Create() (*netv1.Ingress, error) { // nolint:golint,unparam
+	obj := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.Name(),
+			Labels: b.labels(),
+		},
+		Spec: netv1.IngressSpec{
+			Rules: b.rules(),
+		},
+	}
+
+	if b.settings.DeploymentIngressClass != "" {
+		className := b.settings.DeploymentIngressClass
+		obj.Spec.IngressClassName = &className
+	}
+
+	return obj, nil
+}
+
+func (b *ingress) Update(obj *netv1.Ingress) (*netv1.Ingress, error) { // nolint:golint,unparam
+	obj.Labels = b.labels()
+	obj.Spec.Rules = b.rules()
+
+	if b.settings.DeploymentIngressClass != "" {
+		className := b.settings.DeploymentIngressClass
+		obj.Spec.IngressClassName = &className
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// addProbes sets the health checks of the service on the container
+func (b *Workload) addProbes(kcontainer *corev1.Container) {
+	probes := b.deployment.ManifestGroup().Services[b.serviceIdx].Probes
+	if probes == nil {
+		return
+	}
+
+	kcontainer.LivenessProbe = kubeProbe(probes.Liveness)
+	kcontainer.ReadinessProbe = kubeProbe(probes.Readiness)
+	kcontainer.StartupProbe = kubeProbe(probes.Startup)
+}
+
+// kubeProbe converts the probe, the zero thresholds are left to the kubernetes defaults
+func kubeProbe(probe *manifest.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	kprobe := &corev1.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+
+	switch {
+	case probe.HTTPGet != nil:
+		kprobe.HTTPGet = &corev1.HTTPGetAction{
+			Path: probe.HTTPGet.Path,
+			Port: intstr.FromInt(int(probe.HTTPGet.Port)),
+		}
+	case probe.TCPSocket != nil:
+		kprobe.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(int(probe.TCPSocket.Port)),
+		}
+	case probe.Exec != nil:
+		kprobe.Exec = &corev1.ExecAction{
+			Command: probe.Exec.Command,
+		}
+	}
+
+	return kprobe
+}
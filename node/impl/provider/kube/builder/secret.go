@@ -0,0 +1,89 @@
+package builder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistrySecretName is the name of the image pull secret holding the registry credentials of a deployment
+const RegistrySecretName = "titan-registry-credentials"
+
+type RegistrySecret interface {
+	builderBase
+	Create() (*corev1.Secret, error)
+	Update(obj *corev1.Secret) (*corev1.Secret, error)
+	Any() bool
+}
+
+type registrySecret struct {
+	builder
+}
+
+var _ RegistrySecret = (*registrySecret)(nil)
+
+// BuildRegistrySecret builds the kubernetes.io/dockerconfigjson secret of the deployment registry credentials
+func BuildRegistrySecret(settings Settings, deployment IClusterDeployment) RegistrySecret {
+	return &registrySecret{builder: builder{settings: settings, deployment: deployment}}
+}
+
+func (b *registrySecret) Name() string {
+	return RegistrySecretName
+}
+
+func (b *registrySecret) Any() bool {
+	return len(b.deployment.ManifestGroup().RegistryCredentials) > 0
+}
+
+func (b *registrySecret) Create() (*corev1.Secret, error) {
+	config, err := b.dockerConfigJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.Name(),
+			Labels: b.labels(),
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: config},
+	}, nil
+}
+
+func (b *registrySecret) Update(obj *corev1.Secret) (*corev1.Secret, error) {
+	config, err := b.dockerConfigJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Labels = b.labels()
+	obj.Type = corev1.SecretTypeDockerConfigJson
+	obj.Data = map[string][]byte{corev1.DockerConfigJsonKey: config}
+	return obj, nil
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+type dockerConfig struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+func (b *registrySecret) dockerConfigJSON() ([]byte, error) {
+	config := dockerConfig{Auths: make(map[string]dockerConfigEntry)}
+	for _, credential := range b.deployment.ManifestGroup().RegistryCredentials {
+		config.Auths[credential.Server] = dockerConfigEntry{
+			Username: credential.Username,
+			Password: credential.Password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password)),
+		}
+	}
+
+	return json.Marshal(config)
+}
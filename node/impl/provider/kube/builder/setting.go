@@ -5,6 +5,8 @@ import (
 
 	"golang.org/x/xerrors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Settings configures k8s object generation such that it is customized to the
@@ -21,6 +23,9 @@ type Settings struct {
 	// Ingress domain to map deployments to
 	DeploymentIngressDomain string
 
+	// Ingress class of the ingresses, the cluster default class if empty
+	DeploymentIngressClass string
+
 	// Return load balancer host in lease status command ?
 	// gcp:    true
 	// others: optional
@@ -41,6 +46,10 @@ type Settings struct {
 
 	// Name of the image pull secret to use in pod spec
 	DockerImagePullSecretsName string
+
+	// Rolling update parameters of deployments, an absolute number or a percentage, e.g. "1" or "25%"
+	DeploymentMaxSurge       string
+	DeploymentMaxUnavailable string
 }
 
 var ErrSettingsValidation = xerrors.New("settings validation")
@@ -51,20 +60,83 @@ func ValidateSettings(settings Settings) error {
 			return fmt.Errorf("%w: empty ingress domain", ErrSettingsValidation)
 		}
 
-		if isDomainName(settings.DeploymentIngressDomain) {
+		if !isDomainName(settings.DeploymentIngressDomain) {
 			return fmt.Errorf("%w: invalid domain name %q", ErrSettingsValidation, settings.DeploymentIngressDomain)
 		}
 	}
 
+	if settings.ClusterPublicHostname != "" && !isDomainName(settings.ClusterPublicHostname) {
+		return fmt.Errorf("%w: invalid cluster public hostname %q", ErrSettingsValidation, settings.ClusterPublicHostname)
+	}
+
+	for name, level := range map[string]float64{
+		"cpu":     settings.CPUCommitLevel,
+		"gpu":     settings.GPUCommitLevel,
+		"memory":  settings.MemoryCommitLevel,
+		"storage": settings.StorageCommitLevel,
+	} {
+		if level < 0 {
+			return fmt.Errorf("%w: %s commit level can not be negative", ErrSettingsValidation, name)
+		}
+	}
+
+	if settings.DeploymentRuntimeClass != "" {
+		if errs := validation.IsDNS1123Subdomain(settings.DeploymentRuntimeClass); len(errs) > 0 {
+			return fmt.Errorf("%w: invalid runtime class %q", ErrSettingsValidation, settings.DeploymentRuntimeClass)
+		}
+	}
+
+	if settings.DockerImagePullSecretsName != "" {
+		if errs := validation.IsDNS1123Subdomain(settings.DockerImagePullSecretsName); len(errs) > 0 {
+			return fmt.Errorf("%w: invalid image pull secrets name %q", ErrSettingsValidation, settings.DockerImagePullSecretsName)
+		}
+	}
+
+	maxSurge, err := validateRollingUpdateValue(settings.DeploymentMaxSurge)
+	if err != nil {
+		return fmt.Errorf("%w: invalid max surge: %s", ErrSettingsValidation, err)
+	}
+
+	maxUnavailable, err := validateRollingUpdateValue(settings.DeploymentMaxUnavailable)
+	if err != nil {
+		return fmt.Errorf("%w: invalid max unavailable: %s", ErrSettingsValidation, err)
+	}
+
+	if maxSurge == 0 && maxUnavailable == 0 {
+		return fmt.Errorf("%w: max surge and max unavailable can not both be zero", ErrSettingsValidation)
+	}
+
 	return nil
 }
 
+// validateRollingUpdateValue parses a rolling update parameter scaled to a total of 100,
+// an empty value means the kubernetes default which is not zero
+func validateRollingUpdateValue(value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+
+	v := intstr.Parse(value)
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&v, 100, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if scaled < 0 {
+		return 0, fmt.Errorf("%q can not be negative", value)
+	}
+
+	return scaled, nil
+}
+
 func NewDefaultSettings() Settings {
 	return Settings{
 		DeploymentServiceType:          corev1.ServiceTypeClusterIP,
 		DeploymentIngressStaticHosts:   false,
 		DeploymentIngressExposeLBHosts: false,
 		NetworkPoliciesEnabled:         false,
+		DeploymentMaxSurge:             "25%",
+		DeploymentMaxUnavailable:       "25%",
 	}
 }
 
@@ -40,17 +40,19 @@ func (b *statefulSet) Create() (*appsv1.StatefulSet, error) { // nolint:golint,u
 			Replicas: b.replicas(),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: b.labels(),
+					Labels:      b.labels(),
+					Annotations: b.podAnnotations(nil),
 				},
 				Spec: corev1.PodSpec{
 					// Affinity:         b.affinity(),
-					// RuntimeClassName: b.runtimeClass(),
+					RuntimeClassName: b.runtimeClass(),
 					SecurityContext: &corev1.PodSecurityContext{
 						RunAsNonRoot: &falseValue,
 					},
 					AutomountServiceAccountToken: &falseValue,
 					Containers:                   []corev1.Container{b.container()},
 					ImagePullSecrets:             b.imagePullSecrets(),
+					Volumes:                      b.configVolumes(),
 				},
 			},
 			VolumeClaimTemplates: b.persistentVolumeClaims(),
@@ -65,11 +67,13 @@ func (b *statefulSet) Update(obj *appsv1.StatefulSet) (*appsv1.StatefulSet, erro
 	obj.Spec.Selector.MatchLabels = b.labels()
 	obj.Spec.Replicas = b.replicas()
 	obj.Spec.Template.Labels = b.labels()
+	obj.Spec.Template.Annotations = b.podAnnotations(obj.Spec.Template.Annotations)
 	// obj.Spec.Template.Spec.Affinity = b.affinity()
-	// obj.Spec.Template.Spec.RuntimeClassName = b.runtimeClass()
+	obj.Spec.Template.Spec.RuntimeClassName = b.runtimeClass()
 	obj.Spec.Template.Spec.Containers = []corev1.Container{b.container()}
 	obj.Spec.Template.Spec.ImagePullSecrets = b.imagePullSecrets()
-	obj.Spec.VolumeClaimTemplates = b.persistentVolumeClaims()
+	obj.Spec.Template.Spec.Volumes = b.configVolumes()
+	// the volume claim templates of a statefulset are immutable, the volumes keep the claims they were created with
 
 	return obj, nil
 }
@@ -1,7 +1,6 @@
 package builder
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
@@ -15,7 +14,10 @@ import (
 
 const (
 	StorageAttributePersistent = "persistent"
+	StorageAttributeClass      = "class"
 	StorageClassDefault        = "default"
+
+	envVarTitanClusterPublicHostname = "TITAN_CLUSTER_PUBLIC_HOSTNAME"
 )
 
 type workloadBase interface {
@@ -44,6 +46,16 @@ func (b *Workload) Name() string {
 	return b.deployment.ManifestGroup().Services[b.serviceIdx].Name
 }
 
+// Requests returns the resources requested by a single pod of the service
+func (b *Workload) Requests() corev1.ResourceList {
+	return b.container().Resources.Requests
+}
+
+// PersistentVolumeClaims returns the claim templates of the persistent volumes of the service
+func (b *Workload) PersistentVolumeClaims() []corev1.PersistentVolumeClaim {
+	return b.persistentVolumeClaims()
+}
+
 func (b *Workload) replicas() *int32 {
 	replicas := new(int32)
 	*replicas = int32(b.deployment.ManifestGroup().Services[b.serviceIdx].Count)
@@ -58,11 +70,16 @@ func (b *Workload) labels() map[string]string {
 }
 
 func (b *Workload) imagePullSecrets() []corev1.LocalObjectReference {
-	if b.settings.DockerImagePullSecretsName == "" {
-		return nil
+	var secrets []corev1.LocalObjectReference
+	if b.settings.DockerImagePullSecretsName != "" {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: b.settings.DockerImagePullSecretsName})
+	}
+
+	if len(b.deployment.ManifestGroup().RegistryCredentials) > 0 {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: RegistrySecretName})
 	}
 
-	return []corev1.LocalObjectReference{{Name: b.settings.DockerImagePullSecretsName}}
+	return secrets
 }
 
 func (b *Workload) container() corev1.Container {
@@ -122,6 +139,7 @@ func (b *Workload) container() corev1.Container {
 			})
 		}
 	}
+	kcontainer.VolumeMounts = append(kcontainer.VolumeMounts, b.configVolumeMounts()...)
 
 	envVarsAdded := make(map[string]int)
 	for _, env := range service.Env {
@@ -134,6 +152,10 @@ func (b *Workload) container() corev1.Container {
 		}
 		envVarsAdded[parts[0]] = 0
 	}
+	for _, env := range b.configEnv() {
+		kcontainer.Env = append(kcontainer.Env, env)
+		envVarsAdded[env.Name] = 0
+	}
 	kcontainer.Env = b.addEnvVarsForDeployment(envVarsAdded, kcontainer.Env)
 
 	for _, expose := range service.Expose {
@@ -142,11 +164,7 @@ func (b *Workload) container() corev1.Container {
 		})
 	}
 
-	buf, err := json.Marshal(kcontainer)
-	if err != nil {
-		fmt.Printf("Marshal err %s", err.Error())
-	}
-	fmt.Printf("deployment %#v", string(buf))
+	b.addProbes(&kcontainer)
 
 	return kcontainer
 }
@@ -183,10 +201,45 @@ func (b *Workload) addEnvVarsForDeployment(envVarsAlreadyAdded map[string]int, e
 	// env = addIfNotPresent(envVarsAlreadyAdded, env, envVarAkashOwner, lid.Owner)
 	// env = addIfNotPresent(envVarsAlreadyAdded, env, envVarAkashProvider, lid.Provider)
 	// env = addIfNotPresent(envVarsAlreadyAdded, env, envVarAkashClusterPublicHostname, b.settings.ClusterPublicHostname)
+	if b.settings.ClusterPublicHostname != "" {
+		env = addIfNotPresent(envVarsAlreadyAdded, env, envVarTitanClusterPublicHostname, b.settings.ClusterPublicHostname)
+	}
 
 	return env
 }
 
+func addIfNotPresent(envVarsAlreadyAdded map[string]int, env []corev1.EnvVar, key string, value string) []corev1.EnvVar {
+	if _, exists := envVarsAlreadyAdded[key]; exists {
+		return env
+	}
+
+	return append(env, corev1.EnvVar{Name: key, Value: value})
+}
+
+func (b *Workload) runtimeClass() *string {
+	if b.settings.DeploymentRuntimeClass == "" {
+		return nil
+	}
+
+	runtimeClass := b.settings.DeploymentRuntimeClass
+	return &runtimeClass
+}
+
+// PersistentService reports whether the service has persistent storage and must run as a statefulset
+func PersistentService(service *manifest.Service) bool {
+	if service.Resources == nil {
+		return false
+	}
+
+	for _, storage := range service.Resources.Storage {
+		attr := storage.Attributes.Find(StorageAttributePersistent)
+		if persistent, _ := attr.AsBool(); persistent {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *Workload) persistentVolumeClaims() []corev1.PersistentVolumeClaim {
 	var pvcs []corev1.PersistentVolumeClaim // nolint:prealloc
 
@@ -217,7 +270,7 @@ func (b *Workload) persistentVolumeClaims() []corev1.PersistentVolumeClaim {
 
 		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.NewQuantity(int64(storage.Quantity.Val.Uint64()), resource.DecimalSI).DeepCopy()
 
-		attr = storage.Attributes.Find(StorageAttributePersistent)
+		attr = storage.Attributes.Find(StorageAttributeClass)
 		if class, valid := attr.AsString(); valid && class != StorageClassDefault {
 			pvc.Spec.StorageClassName = &class
 		}
@@ -0,0 +1,274 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	netlisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// cacheResyncPeriod is how often the informers replay their caches to the handlers
+var cacheResyncPeriod = 10 * time.Minute
+
+// cacheSyncTimeout bounds the initial list of the objects by the informers
+var cacheSyncTimeout = time.Minute
+
+// Cache answers the queries of the titan labelled objects from shared informers instead of the API server.
+// The caches follow the cluster with a small delay, the write paths keep reading from the API server.
+// Events are not labelled and are listed from the API server within the namespace of a deployment.
+type Cache interface {
+	GetNS(name string) (*corev1.Namespace, error)
+	ListNS(selector labels.Selector) (*corev1.NamespaceList, error)
+	ListDeployments(ns string) (*appsv1.DeploymentList, error)
+	ListStatefulSets(ns string) (*appsv1.StatefulSetList, error)
+	ListServices(ns string) (*corev1.ServiceList, error)
+	// ListIngresses lists the ingresses of the namespace, or of every namespace with metav1.NamespaceAll
+	ListIngresses(ns string) (*netv1.IngressList, error)
+	ListPods(ns string, selector labels.Selector) (*corev1.PodList, error)
+	// Subscribe returns the names of the namespaces whose objects changed, the changes of a namespace
+	// not read yet are merged. The channel is closed when the context is done.
+	Subscribe(ctx context.Context) <-chan string
+}
+
+type informerCache struct {
+	namespaces   corelisters.NamespaceLister
+	deployments  appslisters.DeploymentLister
+	statefulSets appslisters.StatefulSetLister
+	services     corelisters.ServiceLister
+	ingresses    netlisters.IngressLister
+	pods         corelisters.PodLister
+
+	lk          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	lk      sync.Mutex
+	pending map[string]struct{}
+	signal  chan struct{}
+}
+
+// NewCache starts the informers of the titan labelled objects until the context is done,
+// it returns once their caches are synced. The informers keep running on error until the context is done.
+func NewCache(ctx context.Context, kc kubernetes.Interface) (Cache, error) {
+	selector := labels.Set{builder.TitanManagedLabelName: "true"}.String()
+
+	// the informers retry listing forever, fail fast if the api server can not be reached
+	if _, err := kc.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector, Limit: 1}); err != nil {
+		return nil, err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(kc, cacheResyncPeriod,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector
+		}))
+
+	c := &informerCache{
+		namespaces:   factory.Core().V1().Namespaces().Lister(),
+		deployments:  factory.Apps().V1().Deployments().Lister(),
+		statefulSets: factory.Apps().V1().StatefulSets().Lister(),
+		services:     factory.Core().V1().Services().Lister(),
+		ingresses:    factory.Networking().V1().Ingresses().Lister(),
+		pods:         factory.Core().V1().Pods().Lister(),
+		subscribers:  make(map[*subscriber]struct{}),
+	}
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.changed,
+		UpdateFunc: func(_, obj interface{}) { c.changed(obj) },
+		DeleteFunc: c.changed,
+	}
+	for _, informer := range []cache.SharedIndexInformer{
+		factory.Core().V1().Namespaces().Informer(),
+		factory.Apps().V1().Deployments().Informer(),
+		factory.Apps().V1().StatefulSets().Informer(),
+		factory.Core().V1().Services().Informer(),
+		factory.Networking().V1().Ingresses().Informer(),
+		factory.Core().V1().Pods().Informer(),
+	} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return nil, err
+		}
+	}
+
+	factory.Start(ctx.Done())
+
+	syncCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+
+	for informerType, ok := range factory.WaitForCacheSync(syncCtx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("timed out syncing the cache of %v", informerType)
+		}
+	}
+
+	return c, nil
+}
+
+// changed notifies the subscribers of the namespace of the object
+func (c *informerCache) changed(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	ns := object.GetNamespace()
+	if _, ok := obj.(*corev1.Namespace); ok {
+		ns = object.GetName()
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	for s := range c.subscribers {
+		s.lk.Lock()
+		s.pending[ns] = struct{}{}
+		s.lk.Unlock()
+
+		select {
+		case s.signal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *informerCache) Subscribe(ctx context.Context) <-chan string {
+	s := &subscriber{pending: make(map[string]struct{}), signal: make(chan struct{}, 1)}
+
+	c.lk.Lock()
+	c.subscribers[s] = struct{}{}
+	c.lk.Unlock()
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer func() {
+			c.lk.Lock()
+			delete(c.subscribers, s)
+			c.lk.Unlock()
+		}()
+
+		for {
+			select {
+			case <-s.signal:
+			case <-ctx.Done():
+				return
+			}
+
+			s.lk.Lock()
+			pending := s.pending
+			s.pending = make(map[string]struct{})
+			s.lk.Unlock()
+
+			for ns := range pending {
+				select {
+				case out <- ns:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (c *informerCache) GetNS(name string) (*corev1.Namespace, error) {
+	return c.namespaces.Get(name)
+}
+
+func (c *informerCache) ListNS(selector labels.Selector) (*corev1.NamespaceList, error) {
+	items, err := c.namespaces.List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &corev1.NamespaceList{Items: make([]corev1.Namespace, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (c *informerCache) ListDeployments(ns string) (*appsv1.DeploymentList, error) {
+	items, err := c.deployments.Deployments(ns).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &appsv1.DeploymentList{Items: make([]appsv1.Deployment, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (c *informerCache) ListStatefulSets(ns string) (*appsv1.StatefulSetList, error) {
+	items, err := c.statefulSets.StatefulSets(ns).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &appsv1.StatefulSetList{Items: make([]appsv1.StatefulSet, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (c *informerCache) ListServices(ns string) (*corev1.ServiceList, error) {
+	items, err := c.services.Services(ns).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &corev1.ServiceList{Items: make([]corev1.Service, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (c *informerCache) ListIngresses(ns string) (*netv1.IngressList, error) {
+	items, err := c.ingresses.Ingresses(ns).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &netv1.IngressList{Items: make([]netv1.Ingress, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (c *informerCache) ListPods(ns string, selector labels.Selector) (*corev1.PodList, error) {
+	items, err := c.pods.Pods(ns).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &corev1.PodList{Items: make([]corev1.Pod, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	titan := map[string]string{builder.TitanManagedLabelName: "true"}
+	kc := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "1111", Labels: titan}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "1111", Labels: titan}},
+	)
+
+	cache, err := NewCache(ctx, kc)
+	require.NoError(t, err)
+
+	nsList, err := cache.ListNS(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, nsList.Items, 1)
+	require.Equal(t, "1111", nsList.Items[0].Name)
+
+	changes := cache.Subscribe(ctx)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "1111", Labels: titan}}
+	_, err = kc.CoreV1().Pods("1111").Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case ns := <-changes:
+		require.Equal(t, "1111", ns)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notified")
+	}
+
+	require.Eventually(t, func() bool {
+		podList, err := cache.ListPods("1111", labels.SelectorFromSet(titan))
+		return err == nil && len(podList.Items) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	deploymentList, err := cache.ListDeployments("1111")
+	require.NoError(t, err)
+	require.Len(t, deploymentList.Items, 1)
+}
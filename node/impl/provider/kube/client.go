@@ -6,34 +6,70 @@ import (
 	"io"
 	"os"
 
+	"github.com/gnasnik/titan-container/metrics"
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
 	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/util/flowcontrol"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type Client interface {
 	Deploy(ctx context.Context, deployment builder.IClusterDeployment) error
 	GetNS(ctx context.Context, ns string) (*v1.Namespace, error)
+	ListNS(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error)
 	DeleteNS(ctx context.Context, ns string) error
 	FetchNodeResources(ctx context.Context) (map[string]*nodeResource, error)
+	FetchPendingResources(ctx context.Context) (corev1.ResourceList, error)
 	ListDeployments(ctx context.Context, ns string) (*appsv1.DeploymentList, error)
+	ListStatefulSets(ctx context.Context, ns string) (*appsv1.StatefulSetList, error)
 	ListServices(ctx context.Context, ns string) (*corev1.ServiceList, error)
+	ListIngresses(ctx context.Context, ns string) (*netv1.IngressList, error)
 	ListPods(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.PodList, error)
-	PodLogs(ctx context.Context, ns string, podName string) (io.ReadCloser, error)
+	PodLogs(ctx context.Context, ns string, podName string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
 	Events(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.EventList, error)
+	PodMetrics(ctx context.Context, ns string, opts metav1.ListOptions) (*metricsv1beta1.PodMetricsList, error)
+	Exec(ctx context.Context, ns string, podName string, opts ExecOptions) error
+	ScaleDeployment(ctx context.Context, ns string, name string, replicas int32) error
+	ScaleStatefulSet(ctx context.Context, ns string, name string, replicas int32) error
+	DeleteDeployment(ctx context.Context, ns string, name string) error
+	DeleteStatefulSet(ctx context.Context, ns string, name string) error
+	DeleteService(ctx context.Context, ns string, name string) error
+	DeleteIngress(ctx context.Context, ns string, name string) error
+	ListSecrets(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.SecretList, error)
+	DeleteSecret(ctx context.Context, ns string, name string) error
+	ListConfigMaps(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.ConfigMapList, error)
+	DeleteConfigMap(ctx context.Context, ns string, name string) error
+	NewCache(ctx context.Context) (Cache, error)
+}
+
+// ExecOptions configures a command executed in a pod container
+type ExecOptions struct {
+	Container         string
+	Command           []string
+	Stdin             io.Reader
+	Stdout            io.Writer
+	Stderr            io.Writer
+	TTY               bool
+	TerminalSizeQueue remotecommand.TerminalSizeQueue
 }
 
 type client struct {
 	kc   kubernetes.Interface
 	metc metricsclient.Interface
+	cfg  *rest.Config
 	log  *logging.ZapEventLogger
 }
 
@@ -82,7 +118,7 @@ func NewClient(configPath string) (Client, error) {
 
 	var log = logging.Logger("client")
 
-	return &client{kc: clientSet, metc: metc, log: log}, nil
+	return &client{kc: clientSet, metc: metc, cfg: config, log: log}, nil
 }
 
 func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployment) error {
@@ -101,14 +137,25 @@ func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployme
 	ns := builder.BuildNS(settings, deployment)
 	if err := applyNS(ctx, c.kc, builder.BuildNS(settings, deployment)); err != nil {
 		c.log.Errorf("applying namespace %s err %s", ns.Name(), err.Error())
+		recordApplyFailure(ctx, "namespace")
 		return err
 	}
 
 	if err := applyNetPolicies(ctx, c.kc, builder.BuildNetPol(settings, deployment)); err != nil { //
 		c.log.Errorf("applying namespace %s network policies err %s", ns.Name(), err)
+		recordApplyFailure(ctx, "network_policy")
 		return err
 	}
 
+	registrySecret := builder.BuildRegistrySecret(settings, deployment)
+	if registrySecret.Any() {
+		if err := applySecret(ctx, c.kc, registrySecret); err != nil {
+			c.log.Errorf("applying namespace %s registry secret err %s", ns.Name(), err)
+			recordApplyFailure(ctx, "secret")
+			return err
+		}
+	}
+
 	// cmanifest := builder.BuildManifest(c.log, settings, c.ns, cdeployment)
 	// if err := applyManifest(ctx, c.ac, cmanifest); err != nil {
 	// 	c.log.Error("applying manifest", "err", err, "lease", lid)
@@ -125,22 +172,34 @@ func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployme
 
 		service := &group.Services[svcIdx]
 
-		persistent := false
-		for i := range service.Resources.Storage {
-			attrVal := service.Resources.Storage[i].Attributes.Find(builder.StorageClassDefault)
-			if persistent, _ = attrVal.AsBool(); persistent {
-				break
+		serviceSecret := builder.BuildServiceSecret(workload)
+		if serviceSecret.Any() {
+			if err := applySecret(ctx, c.kc, serviceSecret); err != nil {
+				c.log.Errorf("applying secret err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "secret")
+				return err
+			}
+		}
+
+		serviceConfigMap := builder.BuildServiceConfigMap(workload)
+		if serviceConfigMap.Any() {
+			if err := applyConfigMap(ctx, c.kc, serviceConfigMap); err != nil {
+				c.log.Errorf("applying config map err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "config_map")
+				return err
 			}
 		}
 
-		if persistent {
+		if builder.PersistentService(service) {
 			if err := applyStatefulSet(ctx, c.kc, builder.BuildStatefulSet(workload)); err != nil {
 				c.log.Errorf("applying statefulSet err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "statefulset")
 				return err
 			}
 		} else {
 			if err := applyDeployment(ctx, c.kc, builder.NewDeployment(workload)); err != nil {
 				c.log.Errorf("applying deployment err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "deployment")
 				return err
 			}
 		}
@@ -154,6 +213,7 @@ func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployme
 		if serviceBuilderLocal.Any() {
 			if err := applyService(ctx, c.kc, serviceBuilderLocal); err != nil {
 				c.log.Error("applying local service err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "service")
 				return err
 			}
 		}
@@ -162,6 +222,16 @@ func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployme
 		if serviceBuilderGlobal.Any() {
 			if err := applyService(ctx, c.kc, serviceBuilderGlobal); err != nil {
 				c.log.Error("applying global service err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "service")
+				return err
+			}
+		}
+
+		ingressBuilder := builder.BuildIngress(workload)
+		if ingressBuilder.Any() {
+			if err := applyIngress(ctx, c.kc, ingressBuilder); err != nil {
+				c.log.Errorf("applying ingress err %s, ns %s, service %s", err.Error(), ns.Name(), service.Name)
+				recordApplyFailure(ctx, "ingress")
 				return err
 			}
 		}
@@ -170,6 +240,12 @@ func (c *client) Deploy(ctx context.Context, deployment builder.IClusterDeployme
 	return nil
 }
 
+// recordApplyFailure counts a kubernetes object of the kind that failed to apply
+func recordApplyFailure(ctx context.Context, kind string) {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.ObjectKind, kind))
+	stats.Record(ctx, metrics.KubeApplyFailure.M(1))
+}
+
 func (c *client) DeleteNS(ctx context.Context, ns string) error {
 	return c.kc.CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
 }
@@ -178,22 +254,130 @@ func (c *client) GetNS(ctx context.Context, ns string) (*v1.Namespace, error) {
 	return c.kc.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
 }
 
+func (c *client) ListNS(ctx context.Context, opts metav1.ListOptions) (*v1.NamespaceList, error) {
+	return c.kc.CoreV1().Namespaces().List(ctx, opts)
+}
+
 func (c *client) ListServices(ctx context.Context, ns string) (*corev1.ServiceList, error) {
 	return c.kc.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
 }
 
+func (c *client) ListIngresses(ctx context.Context, ns string) (*netv1.IngressList, error) {
+	return c.kc.NetworkingV1().Ingresses(ns).List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) DeleteIngress(ctx context.Context, ns string, name string) error {
+	return c.kc.NetworkingV1().Ingresses(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (c *client) ListSecrets(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	return c.kc.CoreV1().Secrets(ns).List(ctx, opts)
+}
+
+func (c *client) DeleteSecret(ctx context.Context, ns string, name string) error {
+	return c.kc.CoreV1().Secrets(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (c *client) ListConfigMaps(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	return c.kc.CoreV1().ConfigMaps(ns).List(ctx, opts)
+}
+
+func (c *client) DeleteConfigMap(ctx context.Context, ns string, name string) error {
+	return c.kc.CoreV1().ConfigMaps(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
 func (c *client) ListDeployments(ctx context.Context, ns string) (*appsv1.DeploymentList, error) {
 	return c.kc.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
 }
 
+func (c *client) DeleteDeployment(ctx context.Context, ns string, name string) error {
+	return c.kc.AppsV1().Deployments(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (c *client) DeleteService(ctx context.Context, ns string, name string) error {
+	return c.kc.CoreV1().Services(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (c *client) ListStatefulSets(ctx context.Context, ns string) (*appsv1.StatefulSetList, error) {
+	return c.kc.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) DeleteStatefulSet(ctx context.Context, ns string, name string) error {
+	return c.kc.AppsV1().StatefulSets(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
 func (c *client) ListPods(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.PodList, error) {
 	return c.kc.CoreV1().Pods(ns).List(ctx, opts)
 }
 
-func (c *client) PodLogs(ctx context.Context, ns string, podName string) (io.ReadCloser, error) {
-	return c.kc.CoreV1().Pods(ns).GetLogs(podName, &corev1.PodLogOptions{}).Stream(context.Background())
+func (c *client) PodLogs(ctx context.Context, ns string, podName string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return c.kc.CoreV1().Pods(ns).GetLogs(podName, opts).Stream(ctx)
 }
 
 func (c *client) Events(ctx context.Context, ns string, opts metav1.ListOptions) (*corev1.EventList, error) {
 	return c.kc.CoreV1().Events(ns).List(ctx, opts)
 }
+
+// PodMetrics lists the current resource usage of the pods reported by the metrics api
+func (c *client) PodMetrics(ctx context.Context, ns string, opts metav1.ListOptions) (*metricsv1beta1.PodMetricsList, error) {
+	return c.metc.MetricsV1beta1().PodMetricses(ns).List(ctx, opts)
+}
+
+func (c *client) NewCache(ctx context.Context) (Cache, error) {
+	return NewCache(ctx, c.kc)
+}
+
+func (c *client) ScaleDeployment(ctx context.Context, ns string, name string, replicas int32) error {
+	scale, err := c.kc.AppsV1().Deployments(ns).GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	scale.Spec.Replicas = replicas
+	_, err = c.kc.AppsV1().Deployments(ns).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *client) ScaleStatefulSet(ctx context.Context, ns string, name string, replicas int32) error {
+	scale, err := c.kc.AppsV1().StatefulSets(ns).GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	scale.Spec.Replicas = replicas
+	_, err = c.kc.AppsV1().StatefulSets(ns).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *client) Exec(ctx context.Context, ns string, podName string, opts ExecOptions) error {
+	req := c.kc.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(ns).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: opts.Container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:             opts.Stdin,
+		Stdout:            opts.Stdout,
+		Tty:               opts.TTY,
+		TerminalSizeQueue: opts.TerminalSizeQueue,
+	}
+	if !opts.TTY {
+		streamOpts.Stderr = opts.Stderr
+	}
+
+	return executor.StreamWithContext(ctx, streamOpts)
+}
@@ -1,6 +1,14 @@
 package manifest
 
 type Group struct {
-	Name     string
-	Services []Service
+	Name                string
+	Services            []Service
+	RegistryCredentials []RegistryCredential
+}
+
+// RegistryCredential authenticates the image pulls from a private registry
+type RegistryCredential struct {
+	Server   string
+	Username string
+	Password string
 }
@@ -0,0 +1,34 @@
+package manifest
+
+// ServiceProbes are the health checks of the service containers
+type ServiceProbes struct {
+	Liveness  *Probe
+	Readiness *Probe
+	Startup   *Probe
+}
+
+// Probe is a health check of a container, only one of HTTPGet, TCPSocket and Exec is set
+type Probe struct {
+	HTTPGet   *HTTPGetProbe
+	TCPSocket *TCPSocketProbe
+	Exec      *ExecProbe
+
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+	TimeoutSeconds      int32
+	SuccessThreshold    int32
+	FailureThreshold    int32
+}
+
+type HTTPGetProbe struct {
+	Path string
+	Port uint32
+}
+
+type TCPSocketProbe struct {
+	Port uint32
+}
+
+type ExecProbe struct {
+	Command []string
+}
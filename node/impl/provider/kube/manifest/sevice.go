@@ -10,4 +10,16 @@ type Service struct {
 	Count     int32
 	Expose    []*ServiceExpose
 	Params    *ServiceParams
+	Probes    *ServiceProbes
+	// Secrets are materialized as a secret and ConfigFiles as a config map of the service
+	Secrets     []ServiceData
+	ConfigFiles []ServiceData
+}
+
+// ServiceData is a value exposed to the service containers as an environment variable, a file or both
+type ServiceData struct {
+	Key       string
+	Value     string
+	Env       string
+	MountPath string
 }
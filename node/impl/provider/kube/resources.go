@@ -63,6 +63,40 @@ func (c *client) FetchNodeResources(ctx context.Context) (map[string]*nodeResour
 	return nodeResources, nil
 }
 
+// FetchPendingResources returns the sum of the resources requested by the pods not scheduled on a node yet
+func (c *client) FetchPendingResources(ctx context.Context) (corev1.ResourceList, error) {
+	podsClient := c.kc.CoreV1().Pods(metav1.NamespaceAll)
+	podsPager := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return podsClient.List(ctx, opts)
+	})
+
+	pending := make(corev1.ResourceList)
+	podListOptions := metav1.ListOptions{
+		FieldSelector: "spec.nodeName=,status.phase=Pending",
+	}
+	err := podsPager.EachListItem(ctx, podListOptions, func(obj runtime.Object) error {
+		pod := obj.(*corev1.Pod)
+		for _, container := range pod.Spec.Containers {
+			addResources(pending, container.Resources.Requests)
+		}
+		addResources(pending, pod.Spec.Overhead)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func addResources(sum corev1.ResourceList, rl corev1.ResourceList) {
+	for name, quantity := range rl {
+		total := sum[name]
+		total.Add(quantity)
+		sum[name] = total
+	}
+}
+
 func (c *client) nodeIsActive(node corev1.Node) bool {
 	ready := false
 	issues := 0
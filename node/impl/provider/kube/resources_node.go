@@ -47,6 +47,24 @@ func newNodeResource(nodeStatus *corev1.NodeStatus) *nodeResource {
 	return nr
 }
 
+// Free returns the allocatable resources of the node not requested by its pods yet
+func (nr *nodeResource) Free() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:              nr.CPU.free(),
+		corev1.ResourceMemory:           nr.Memory.free(),
+		corev1.ResourceEphemeralStorage: nr.EphemeralStorage.free(),
+	}
+}
+
+func (ri resourceItem) free() resource.Quantity {
+	free := ri.Allocatable.DeepCopy()
+	free.Sub(ri.Allocated)
+	if free.Sign() < 0 {
+		return *resource.NewQuantity(0, free.Format)
+	}
+	return free
+}
+
 func (nr *nodeResource) addAllocatedResources(rl corev1.ResourceList) {
 	for name, quantity := range rl {
 		switch name {
@@ -1,9 +1,13 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"sync"
 
 	"github.com/gnasnik/titan-container/api/types"
 	"github.com/gnasnik/titan-container/node/config"
@@ -11,34 +15,151 @@ import (
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
 	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
 	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/fx"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 var log = logging.Logger("provider")
 
+const (
+	logStreamBufferSize = 64
+	maxLogLineSize      = 1 << 20
+)
+
 type Manager interface {
 	GetStatistics(ctx context.Context) (*types.ResourcesStatistics, error)
+	CheckCapacity(ctx context.Context, deployment *types.Deployment) error
 	CreateDeployment(ctx context.Context, deployment *types.Deployment) error
 	UpdateDeployment(ctx context.Context, deployment *types.Deployment) error
 	CloseDeployment(ctx context.Context, deployment *types.Deployment) error
 	GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error)
+	GetAppliedDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error)
+	ListDeployments(ctx context.Context) ([]*types.Deployment, error)
+	WatchDeployments(ctx context.Context) (<-chan *types.Deployment, error)
 	GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.ServiceLog, error)
+	GetLogStream(ctx context.Context, id types.DeploymentID, opt *types.LogOption) (<-chan *types.LogLine, error)
 	GetEvents(ctx context.Context, id types.DeploymentID) ([]*types.ServiceEvent, error)
+	GetDeploymentMetrics(ctx context.Context, id types.DeploymentID) (*types.DeploymentMetrics, error)
+	ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error
+	ExecDeployment(ctx context.Context, id types.DeploymentID, opt *types.ExecOption) (<-chan *types.ExecOutput, error)
+	ExecInput(ctx context.Context, input *types.ExecInput) error
 }
 
 type manager struct {
-	kc          kube.Client
-	providerCfg *config.ProviderCfg
+	kc           kube.Client
+	providerCfg  *config.ProviderCfg
+	settings     builder.Settings
+	execSessions *execSessions
+
+	// the reads of the deployments are served from the informer cache, it is started by the first read
+	cacheLk     sync.Mutex
+	cache       kube.Cache
+	cancelCache context.CancelFunc
 }
 
 var _ Manager = (*manager)(nil)
 
-func NewManager(config *config.ProviderCfg) (Manager, error) {
+func NewManager(lc fx.Lifecycle, config *config.ProviderCfg) (Manager, error) {
+	settings := clusterSettings(config)
+	if err := builder.ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
 	client, err := kube.NewClient(config.KubeConfigPath)
 	if err != nil {
 		return nil, err
 	}
-	return &manager{kc: client, providerCfg: config}, nil
+	m := &manager{
+		kc:           client,
+		providerCfg:  config,
+		settings:     settings,
+		execSessions: &execSessions{sessions: make(map[string]*execSession)},
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			// warm the cache up without blocking the start, a failed start is retried by the next read
+			go func() {
+				if _, err := m.getCache(); err != nil {
+					log.Errorf("start kubernetes cache: %s", err.Error())
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			m.stopCache()
+			return nil
+		},
+	})
+
+	return m, nil
+}
+
+// getCache returns the informer cache of the titan objects, starting it if it is not running
+func (m *manager) getCache() (kube.Cache, error) {
+	m.cacheLk.Lock()
+	defer m.cacheLk.Unlock()
+
+	if m.cache != nil {
+		return m.cache, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cache, err := m.kc.NewCache(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	m.cache, m.cancelCache = cache, cancel
+	return cache, nil
+}
+
+func (m *manager) stopCache() {
+	m.cacheLk.Lock()
+	defer m.cacheLk.Unlock()
+
+	if m.cancelCache != nil {
+		m.cancelCache()
+	}
+	m.cache, m.cancelCache = nil, nil
+}
+
+// clusterSettings returns the settings used to build the kubernetes objects from the provider config
+func clusterSettings(cfg *config.ProviderCfg) builder.Settings {
+	settings := builder.NewDefaultSettings()
+	settings.ClusterPublicHostname = cfg.Cluster.PublicHostname
+	settings.NetworkPoliciesEnabled = cfg.Cluster.NetworkPoliciesEnabled
+	settings.CPUCommitLevel = cfg.Cluster.CPUCommitLevel
+	settings.MemoryCommitLevel = cfg.Cluster.MemoryCommitLevel
+	settings.StorageCommitLevel = cfg.Cluster.StorageCommitLevel
+	settings.DeploymentRuntimeClass = cfg.Cluster.RuntimeClass
+	settings.DockerImagePullSecretsName = cfg.Cluster.ImagePullSecretsName
+
+	if cfg.Cluster.RollingUpdateMaxSurge != "" {
+		settings.DeploymentMaxSurge = cfg.Cluster.RollingUpdateMaxSurge
+	}
+	if cfg.Cluster.RollingUpdateMaxUnavailable != "" {
+		settings.DeploymentMaxUnavailable = cfg.Cluster.RollingUpdateMaxUnavailable
+	}
+
+	if cfg.Ingress.Enabled && cfg.Ingress.Domain != "" {
+		settings.DeploymentIngressStaticHosts = true
+		settings.DeploymentIngressDomain = cfg.Ingress.Domain
+	}
+	settings.DeploymentIngressClass = cfg.Ingress.ClassName
+
+	return settings
+}
+
+// ingressEnabled reports whether the http ports of the deployment are routed through an ingress
+func (m *manager) ingressEnabled(deployment *types.Deployment) bool {
+	return m.providerCfg.Ingress.Enabled && deployment.Type == types.DeploymentTypeWeb
 }
 
 func (m *manager) GetStatistics(ctx context.Context) (*types.ResourcesStatistics, error) {
@@ -70,11 +191,20 @@ func (m *manager) GetStatistics(ctx context.Context) (*types.ResourcesStatistics
 	statistics.Memory.Available = statistics.Memory.Available - statistics.Memory.Active
 	statistics.Storage.Available = statistics.Storage.Available - statistics.Storage.Active
 
+	pending, err := m.kc.FetchPendingResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics.CPUCores.Pending = pending.Cpu().AsApproximateFloat64()
+	statistics.Memory.Pending = uint64(pending.Memory().AsApproximateFloat64())
+	statistics.Storage.Pending = uint64(pending.StorageEphemeral().AsApproximateFloat64())
+
 	return statistics, nil
 }
 
 func (m *manager) CreateDeployment(ctx context.Context, deployment *types.Deployment) error {
-	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment)
+	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment, m.ingressEnabled(deployment))
 	if err != nil {
 		log.Errorf("CreateDeployment %s", err.Error())
 		return err
@@ -93,12 +223,32 @@ func (m *manager) CreateDeployment(ctx context.Context, deployment *types.Deploy
 		return fmt.Errorf("deployment %s already exist", deployment.ID)
 	}
 
-	ctx = context.WithValue(ctx, builder.SettingsKey, builder.NewDefaultSettings())
+	statefulSetList, err := m.kc.ListStatefulSets(context.Background(), ns)
+	if err != nil {
+		log.Errorf("ListStatefulSets %s", err.Error())
+		return err
+	}
+
+	if statefulSetList != nil && len(statefulSetList.Items) > 0 {
+		return fmt.Errorf("deployment %s already exist", deployment.ID)
+	}
+
+	if err := m.checkHosts(ns, k8sDeployment); err != nil {
+		log.Errorf("CreateDeployment %s", err.Error())
+		return err
+	}
+
+	if err := m.checkCapacity(ctx, k8sDeployment); err != nil {
+		log.Errorf("CreateDeployment %s", err.Error())
+		return err
+	}
+
+	ctx = context.WithValue(ctx, builder.SettingsKey, m.settings)
 	return m.kc.Deploy(ctx, k8sDeployment)
 }
 
 func (m *manager) UpdateDeployment(ctx context.Context, deployment *types.Deployment) error {
-	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment)
+	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment, m.ingressEnabled(deployment))
 	if err != nil {
 		log.Errorf("UpdateDeployment %s", err.Error())
 		return err
@@ -112,16 +262,273 @@ func (m *manager) UpdateDeployment(ctx context.Context, deployment *types.Deploy
 		return err
 	}
 
-	if deploymentList == nil || len(deploymentList.Items) == 0 {
+	statefulSetList, err := m.kc.ListStatefulSets(context.Background(), ns)
+	if err != nil {
+		return err
+	}
+
+	if len(deploymentList.Items) == 0 && len(statefulSetList.Items) == 0 {
 		return fmt.Errorf("deployment %s do not exist", deployment.ID)
 	}
 
-	ctx = context.WithValue(ctx, builder.SettingsKey, builder.NewDefaultSettings())
-	return m.kc.Deploy(ctx, k8sDeployment)
+	if err := m.checkVolumes(k8sDeployment, statefulSetList); err != nil {
+		return err
+	}
+
+	if err := m.checkHosts(ns, k8sDeployment); err != nil {
+		return err
+	}
+
+	group := k8sDeployment.ManifestGroup()
+
+	// a service moving between a deployment and a statefulset is recreated with the same name
+	if err := m.pruneWorkloads(ctx, ns, deploymentList, statefulSetList, group); err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, builder.SettingsKey, m.settings)
+	if err := m.kc.Deploy(ctx, k8sDeployment); err != nil {
+		return err
+	}
+
+	if err := m.pruneServices(ctx, ns, k8sDeployment); err != nil {
+		return err
+	}
+
+	return m.pruneConfigs(ctx, ns, k8sDeployment)
+}
+
+// pruneWorkloads deletes the kubernetes deployments and statefulsets in the namespace which are
+// no longer part of the manifest group or no longer match the kind of workload the service runs as
+func (m *manager) pruneWorkloads(ctx context.Context, ns string, deploymentList *appsv1.DeploymentList, statefulSetList *appsv1.StatefulSetList, group *manifest.Group) error {
+	persistent := make(map[string]bool, len(group.Services))
+	for i := range group.Services {
+		persistent[group.Services[i].Name] = builder.PersistentService(&group.Services[i])
+	}
+
+	for _, deployment := range deploymentList.Items {
+		if p, ok := persistent[deployment.Name]; ok && !p {
+			continue
+		}
+
+		if err := m.kc.DeleteDeployment(ctx, ns, deployment.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for _, statefulSet := range statefulSetList.Items {
+		if p, ok := persistent[statefulSet.Name]; ok && p {
+			continue
+		}
+
+		if err := m.kc.DeleteStatefulSet(ctx, ns, statefulSet.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkVolumes returns an error if a service which keeps running as a statefulset changes its persistent volumes,
+// the volume claim templates of a statefulset cannot be updated and the pods would mount volumes without a claim
+func (m *manager) checkVolumes(deployment builder.IClusterDeployment, statefulSetList *appsv1.StatefulSetList) error {
+	current := make(map[string][]corev1.PersistentVolumeClaim, len(statefulSetList.Items))
+	for _, statefulSet := range statefulSetList.Items {
+		current[statefulSet.Name] = statefulSet.Spec.VolumeClaimTemplates
+	}
+
+	for i, service := range deployment.ManifestGroup().Services {
+		claims, ok := current[service.Name]
+		if !ok || !builder.PersistentService(&deployment.ManifestGroup().Services[i]) {
+			continue
+		}
+
+		workload := builder.NewWorkload(m.settings, deployment, i)
+		if !sameVolumeClaims(workload.PersistentVolumeClaims(), claims) {
+			return fmt.Errorf("volumes of service %s cannot be changed", service.Name)
+		}
+	}
+
+	return nil
+}
+
+func sameVolumeClaims(desired, current []corev1.PersistentVolumeClaim) bool {
+	if len(desired) != len(current) {
+		return false
+	}
+
+	for i := range desired {
+		if desired[i].Name != current[i].Name {
+			return false
+		}
+
+		size, currentSize := desired[i].Spec.Resources.Requests[corev1.ResourceStorage], current[i].Spec.Resources.Requests[corev1.ResourceStorage]
+		if !size.Equal(currentSize) {
+			return false
+		}
+
+		class, currentClass := desired[i].Spec.StorageClassName, current[i].Spec.StorageClassName
+		if (class == nil) != (currentClass == nil) || (class != nil && *class != *currentClass) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkHosts returns an error if a custom host of the deployment is under the ingress domain of the provider
+// without being the generated host of its service, or is routed by the ingress of another deployment
+func (m *manager) checkHosts(ns string, deployment builder.IClusterDeployment) error {
+	cache, err := m.getCache()
+	if err != nil {
+		return err
+	}
+
+	ingressList, err := cache.ListIngresses(metav1.NamespaceAll)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]struct{})
+	for _, ingress := range ingressList.Items {
+		if ingress.Namespace == ns {
+			continue
+		}
+		for _, rule := range ingress.Spec.Rules {
+			used[rule.Host] = struct{}{}
+		}
+	}
+
+	for i, service := range deployment.ManifestGroup().Services {
+		ingress := builder.BuildIngress(builder.NewWorkload(m.settings, deployment, i))
+		for _, expose := range service.Expose {
+			for _, host := range expose.Hosts {
+				if ingress.UnderIngressDomain(host) && host != ingress.GeneratedHost() {
+					return fmt.Errorf("host %s of service %s is reserved for the generated hosts", host, service.Name)
+				}
+				if _, ok := used[host]; ok {
+					return fmt.Errorf("host %s of service %s is used by another deployment", host, service.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneServices deletes the kubernetes services and ingresses in the namespace which are no longer exposed by the deployment
+func (m *manager) pruneServices(ctx context.Context, ns string, deployment builder.IClusterDeployment) error {
+	settings := m.settings
+
+	services := make(map[string]struct{})
+	ingresses := make(map[string]struct{})
+	for i := range deployment.ManifestGroup().Services {
+		workload := builder.NewWorkload(settings, deployment, i)
+
+		for _, global := range []bool{false, true} {
+			if service := builder.BuildService(workload, global); service.Any() {
+				services[service.Name()] = struct{}{}
+			}
+		}
+
+		if ingress := builder.BuildIngress(workload); ingress.Any() {
+			ingresses[ingress.Name()] = struct{}{}
+		}
+	}
+
+	serviceList, err := m.kc.ListServices(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range serviceList.Items {
+		if _, ok := services[service.Name]; ok {
+			continue
+		}
+
+		if err := m.kc.DeleteService(ctx, ns, service.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	ingressList, err := m.kc.ListIngresses(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, ingress := range ingressList.Items {
+		if _, ok := ingresses[ingress.Name]; ok {
+			continue
+		}
+
+		if err := m.kc.DeleteIngress(ctx, ns, ingress.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pruneConfigs deletes the secrets and config maps in the namespace which are no longer used by the deployment
+func (m *manager) pruneConfigs(ctx context.Context, ns string, deployment builder.IClusterDeployment) error {
+	settings := m.settings
+
+	secrets := make(map[string]struct{})
+	configMaps := make(map[string]struct{})
+	if registrySecret := builder.BuildRegistrySecret(settings, deployment); registrySecret.Any() {
+		secrets[registrySecret.Name()] = struct{}{}
+	}
+
+	for i := range deployment.ManifestGroup().Services {
+		workload := builder.NewWorkload(settings, deployment, i)
+
+		if secret := builder.BuildServiceSecret(workload); secret.Any() {
+			secrets[secret.Name()] = struct{}{}
+		}
+
+		if configMap := builder.BuildServiceConfigMap(workload); configMap.Any() {
+			configMaps[configMap.Name()] = struct{}{}
+		}
+	}
+
+	// only the objects created by the provider are pruned
+	opts := labelsToListOptions(map[string]string{builder.TitanManagedLabelName: "true"})
+
+	secretList, err := m.kc.ListSecrets(ctx, ns, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secretList.Items {
+		if _, ok := secrets[secret.Name]; ok {
+			continue
+		}
+
+		if err := m.kc.DeleteSecret(ctx, ns, secret.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	configMapList, err := m.kc.ListConfigMaps(ctx, ns, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, configMap := range configMapList.Items {
+		if _, ok := configMaps[configMap.Name]; ok {
+			continue
+		}
+
+		if err := m.kc.DeleteConfigMap(ctx, ns, configMap.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (m *manager) CloseDeployment(ctx context.Context, deployment *types.Deployment) error {
-	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment)
+	k8sDeployment, err := ClusterDeploymentFromDeployment(deployment, false)
 	if err != nil {
 		log.Errorf("CloseDeployment %s", err.Error())
 		return err
@@ -136,11 +543,89 @@ func (m *manager) CloseDeployment(ctx context.Context, deployment *types.Deploym
 	return m.kc.DeleteNS(ctx, ns)
 }
 
-func (m *manager) GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) {
+func (m *manager) ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error {
+	if replicas < 1 {
+		return fmt.Errorf("replicas must be at least 1")
+	}
+
 	deploymentID := manifest.DeploymentID{ID: string(id)}
 	ns := builder.DidNS(deploymentID)
 
 	deploymentList, err := m.kc.ListDeployments(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deploymentList.Items {
+		if deployment.Name == serviceName {
+			return m.kc.ScaleDeployment(ctx, ns, serviceName, int32(replicas))
+		}
+	}
+
+	statefulSetList, err := m.kc.ListStatefulSets(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, statefulSet := range statefulSetList.Items {
+		if statefulSet.Name == serviceName {
+			return m.kc.ScaleStatefulSet(ctx, ns, serviceName, int32(replicas))
+		}
+	}
+
+	return fmt.Errorf("service %s do not exist in deployment %s", serviceName, id)
+}
+
+func (m *manager) GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) {
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.getDeployment(ctx, id, cache)
+}
+
+// GetAppliedDeployment returns the services of the deployment from the API server rather than the cache,
+// the manager reads them right after the deployment is applied.
+func (m *manager) GetAppliedDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) {
+	return m.getDeployment(ctx, id, apiServerLister{ctx: ctx, kc: m.kc})
+}
+
+// workloadLister lists the objects of the namespace of a deployment, kube.Cache lists them from the informers
+type workloadLister interface {
+	ListDeployments(ns string) (*appsv1.DeploymentList, error)
+	ListStatefulSets(ns string) (*appsv1.StatefulSetList, error)
+	ListServices(ns string) (*corev1.ServiceList, error)
+	ListIngresses(ns string) (*netv1.IngressList, error)
+}
+
+// apiServerLister lists the objects from the API server
+type apiServerLister struct {
+	ctx context.Context
+	kc  kube.Client
+}
+
+func (l apiServerLister) ListDeployments(ns string) (*appsv1.DeploymentList, error) {
+	return l.kc.ListDeployments(l.ctx, ns)
+}
+
+func (l apiServerLister) ListStatefulSets(ns string) (*appsv1.StatefulSetList, error) {
+	return l.kc.ListStatefulSets(l.ctx, ns)
+}
+
+func (l apiServerLister) ListServices(ns string) (*corev1.ServiceList, error) {
+	return l.kc.ListServices(l.ctx, ns)
+}
+
+func (l apiServerLister) ListIngresses(ns string) (*netv1.IngressList, error) {
+	return l.kc.ListIngresses(l.ctx, ns)
+}
+
+func (m *manager) getDeployment(ctx context.Context, id types.DeploymentID, lister workloadLister) (*types.Deployment, error) {
+	deploymentID := manifest.DeploymentID{ID: string(id)}
+	ns := builder.DidNS(deploymentID)
+
+	deploymentList, err := lister.ListDeployments(ns)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +635,18 @@ func (m *manager) GetDeployment(ctx context.Context, id types.DeploymentID) (*ty
 		return nil, err
 	}
 
-	serviceList, err := m.kc.ListServices(ctx, ns)
+	statefulSetList, err := lister.ListStatefulSets(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	statefulServices, err := k8sStatefulSetsToServices(statefulSetList)
+	if err != nil {
+		return nil, err
+	}
+	services = append(services, statefulServices...)
+
+	serviceList, err := lister.ListServices(ns)
 	if err != nil {
 		return nil, err
 	}
@@ -167,9 +663,120 @@ func (m *manager) GetDeployment(ctx context.Context, id types.DeploymentID) (*ty
 		}
 	}
 
+	ingressList, err := lister.ListIngresses(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	urlMap := k8sIngressToURLMap(ingressList)
+	for i := range services {
+		services[i].URLs = urlMap[services[i].Name]
+	}
+
+	healthMap, err := m.getServicesHealth(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range services {
+		services[i].Status.Health = healthMap[services[i].Name]
+	}
+
 	return &types.Deployment{ID: id, Services: services, ProviderExposeIP: m.providerCfg.PublicIP}, nil
 }
 
+// ListDeployments returns the deployments of the titan labelled namespaces in the cluster with the state of their rollout,
+// the deployments of the namespaces being deleted are closing.
+func (m *manager) ListDeployments(ctx context.Context) ([]*types.Deployment, error) {
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	nsList, err := cache.ListNS(labels.SelectorFromSet(labels.Set{builder.TitanManagedLabelName: "true"}))
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make([]*types.Deployment, 0, len(nsList.Items))
+	for i := range nsList.Items {
+		deployment, err := m.namespaceDeployment(ctx, &nsList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
+
+// WatchDeployments sends the deployment of a namespace with the state of its rollout every time its objects change,
+// the deployment of a deleted namespace is sent closed.
+func (m *manager) WatchDeployments(ctx context.Context) (<-chan *types.Deployment, error) {
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := cache.Subscribe(ctx)
+
+	out := make(chan *types.Deployment)
+	go func() {
+		defer close(out)
+
+		for ns := range changes {
+			deployment, err := m.observeDeployment(ctx, cache, ns)
+			if err != nil {
+				log.Errorf("observe deployment %s: %s", ns, err.Error())
+				continue
+			}
+
+			select {
+			case out <- deployment:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// observeDeployment returns the deployment of the namespace from the cache
+func (m *manager) observeDeployment(ctx context.Context, cache kube.Cache, name string) (*types.Deployment, error) {
+	ns, err := cache.GetNS(name)
+	if apierrors.IsNotFound(err) {
+		return &types.Deployment{ID: types.DeploymentID(name), State: types.DeploymentStateClose, StateReason: "namespace deleted"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m.namespaceDeployment(ctx, ns)
+}
+
+// namespaceDeployment returns the deployment of the namespace, the deployment of a namespace being deleted is closing
+func (m *manager) namespaceDeployment(ctx context.Context, ns *corev1.Namespace) (*types.Deployment, error) {
+	deployment := &types.Deployment{
+		ID:        types.DeploymentID(ns.Name),
+		CreatedAt: ns.CreationTimestamp.Time,
+	}
+
+	if ns.DeletionTimestamp != nil || ns.Status.Phase == corev1.NamespaceTerminating {
+		deployment.State = types.DeploymentStateClosing
+		deployment.StateReason = "namespace terminating"
+		return deployment, nil
+	}
+
+	var err error
+	deployment.State, deployment.StateReason, err = m.getRolloutState(ctx, ns.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
 func (m *manager) GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.ServiceLog, error) {
 	deploymentID := manifest.DeploymentID{ID: string(id)}
 	ns := builder.DidNS(deploymentID)
@@ -205,6 +812,77 @@ func (m *manager) GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.
 	return serviceLogs, nil
 }
 
+func (m *manager) GetLogStream(ctx context.Context, id types.DeploymentID, opt *types.LogOption) (<-chan *types.LogLine, error) {
+	deploymentID := manifest.DeploymentID{ID: string(id)}
+	ns := builder.DidNS(deploymentID)
+
+	pods, err := m.getPods(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt == nil {
+		opt = &types.LogOption{}
+	}
+
+	logOpts := &corev1.PodLogOptions{Follow: opt.Follow}
+	if opt.TailLines > 0 {
+		logOpts.TailLines = &opt.TailLines
+	}
+	if !opt.SinceTime.IsZero() {
+		sinceTime := metav1.NewTime(opt.SinceTime)
+		logOpts.SinceTime = &sinceTime
+	}
+
+	readers := make(map[string]io.ReadCloser)
+	for podName, serviceName := range pods {
+		if len(opt.ServiceName) > 0 && opt.ServiceName != serviceName {
+			continue
+		}
+
+		reader, err := m.kc.PodLogs(ctx, ns, podName, logOpts)
+		if err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return nil, err
+		}
+		readers[podName] = reader
+	}
+
+	out := make(chan *types.LogLine, logStreamBufferSize)
+
+	var wg sync.WaitGroup
+	for podName, reader := range readers {
+		wg.Add(1)
+		go func(podName string, reader io.ReadCloser) {
+			defer wg.Done()
+			defer reader.Close()
+
+			scanner := bufio.NewScanner(reader)
+			scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLogLineSize)
+			for scanner.Scan() {
+				select {
+				case out <- &types.LogLine{ServiceName: pods[podName], PodName: podName, Line: types.Log(scanner.Text())}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err := scanner.Err(); err != nil && ctx.Err() == nil {
+				log.Errorf("read logs of pod %s: %s", podName, err.Error())
+			}
+		}(podName, reader)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
 func (m *manager) GetEvents(ctx context.Context, id types.DeploymentID) ([]*types.ServiceEvent, error) {
 	deploymentID := manifest.DeploymentID{ID: string(id)}
 	ns := builder.DidNS(deploymentID)
@@ -234,6 +912,9 @@ func (m *manager) GetEvents(ctx context.Context, id types.DeploymentID) ([]*type
 
 	serviceEvents := make([]*types.ServiceEvent, 0, len(serviceEventMap))
 	for serviceName, events := range serviceEventMap {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Time.Before(events[j].Time)
+		})
 		serviceEvent := &types.ServiceEvent{ServiceName: serviceName, Events: events}
 		serviceEvents = append(serviceEvents, serviceEvent)
 	}
@@ -242,7 +923,12 @@ func (m *manager) GetEvents(ctx context.Context, id types.DeploymentID) ([]*type
 }
 
 func (m *manager) getPods(ctx context.Context, ns string) (map[string]string, error) {
-	deploymentList, err := m.kc.ListDeployments(context.Background(), ns)
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentList, err := cache.ListDeployments(ns)
 	if err != nil {
 		return nil, err
 	}
@@ -251,10 +937,22 @@ func (m *manager) getPods(ctx context.Context, ns string) (map[string]string, er
 		return nil, fmt.Errorf("namespace %s do not exist deployment", ns)
 	}
 
-	pods := make(map[string]string)
+	statefulSetList, err := cache.ListStatefulSets(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	selectors := make(map[string]map[string]string)
 	for _, deployment := range deploymentList.Items {
-		labels := deployment.ObjectMeta.Labels
-		podList, err := m.kc.ListPods(context.Background(), ns, labelsToListOptions(labels))
+		selectors[deployment.Name] = deployment.Spec.Selector.MatchLabels
+	}
+	for _, statefulSet := range statefulSetList.Items {
+		selectors[statefulSet.Name] = statefulSet.Spec.Selector.MatchLabels
+	}
+
+	pods := make(map[string]string)
+	for name, matchLabels := range selectors {
+		podList, err := cache.ListPods(ns, labels.SelectorFromSet(matchLabels))
 		if err != nil {
 			return nil, err
 		}
@@ -264,7 +962,7 @@ func (m *manager) getPods(ctx context.Context, ns string) (map[string]string, er
 		}
 
 		for _, pod := range podList.Items {
-			pods[pod.Name] = deployment.Name
+			pods[pod.Name] = name
 		}
 	}
 
@@ -275,7 +973,7 @@ func labelsToListOptions(labels map[string]string) metav1.ListOptions {
 	labelSelector := ""
 	for k, v := range labels {
 		if len(labelSelector) > 0 {
-			labelSelector = fmt.Sprintf("%s;%s=%s", labelSelector, k, v)
+			labelSelector = fmt.Sprintf("%s,%s=%s", labelSelector, k, v)
 		} else {
 			labelSelector = fmt.Sprintf("%s=%s", k, v)
 		}
@@ -285,7 +983,7 @@ func labelsToListOptions(labels map[string]string) metav1.ListOptions {
 }
 
 func (m *manager) getPodLogs(ctx context.Context, ns string, podName string) ([]byte, error) {
-	reader, err := m.kc.PodLogs(ctx, ns, podName)
+	reader, err := m.kc.PodLogs(ctx, ns, podName, &corev1.PodLogOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -317,7 +1015,7 @@ func (m *manager) getEvents(ctx context.Context, ns string) (map[string][]types.
 			events = make([]types.Event, 0)
 		}
 
-		events = append(events, types.Event(event.Message))
+		events = append(events, k8sEventToEvent(&event))
 		eventMap[podName] = events
 	}
 
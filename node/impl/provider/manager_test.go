@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSameVolumeClaims(t *testing.T) {
+	claim := func(name, size string, class *string) corev1.PersistentVolumeClaim {
+		pvc := corev1.PersistentVolumeClaim{}
+		pvc.Name = name
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+		pvc.Spec.StorageClassName = class
+		return pvc
+	}
+	ssd := "ssd"
+
+	current := []corev1.PersistentVolumeClaim{claim("db-data", "1G", nil)}
+
+	// the quantities are compared by value
+	require.True(t, sameVolumeClaims([]corev1.PersistentVolumeClaim{claim("db-data", "1000M", nil)}, current))
+
+	require.False(t, sameVolumeClaims([]corev1.PersistentVolumeClaim{claim("db-data", "2G", nil)}, current))
+	require.False(t, sameVolumeClaims([]corev1.PersistentVolumeClaim{claim("db-data", "1G", &ssd)}, current))
+	require.False(t, sameVolumeClaims([]corev1.PersistentVolumeClaim{claim("db-logs", "1G", nil)}, current))
+	require.False(t, sameVolumeClaims(append(current, claim("db-logs", "1G", nil)), current))
+}
+
+// ingressCache answers the ingresses of every namespace, the other queries are not used
+type ingressCache struct {
+	kube.Cache
+	ingresses netv1.IngressList
+}
+
+func (c *ingressCache) ListIngresses(ns string) (*netv1.IngressList, error) {
+	return &c.ingresses, nil
+}
+
+func TestCheckHosts(t *testing.T) {
+	other := netv1.Ingress{}
+	other.Namespace = "other"
+	other.Spec.Rules = []netv1.IngressRule{{Host: "shop.example.com"}}
+
+	m := &manager{
+		settings: builder.Settings{DeploymentIngressDomain: "titan.io", DeploymentIngressStaticHosts: true},
+		cache:    &ingressCache{ingresses: netv1.IngressList{Items: []netv1.Ingress{other}}},
+	}
+
+	check := func(hosts ...string) error {
+		deployment := &types.Deployment{ID: "d1", Services: []*types.Service{{Name: "web", Image: "nginx", Ports: types.Ports{{Port: 80, Hosts: hosts}}}}}
+		k8sDeployment, err := ClusterDeploymentFromDeployment(deployment, true)
+		require.NoError(t, err)
+		return m.checkHosts(builder.DidNS(k8sDeployment.DeploymentID()), k8sDeployment)
+	}
+
+	require.NoError(t, check("www.example.com"))
+	require.NoError(t, check(builder.DidNS(manifest.DeploymentID{ID: "d1"})+".titan.io"))
+
+	require.ErrorContains(t, check("other.titan.io"), "reserved")
+	require.ErrorContains(t, check("titan.io"), "reserved")
+	require.ErrorContains(t, check("shop.example.com"), "another deployment")
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// GetDeploymentMetrics returns the current resource usage of the pods of the deployment reported by metrics-server
+func (m *manager) GetDeploymentMetrics(ctx context.Context, id types.DeploymentID) (*types.DeploymentMetrics, error) {
+	deploymentID := manifest.DeploymentID{ID: string(id)}
+	ns := builder.DidNS(deploymentID)
+
+	cache, err := m.getCache()
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentList, err := cache.ListDeployments(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSetList, err := cache.ListStatefulSets(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*corev1.PodSpec)
+	for i := range deploymentList.Items {
+		templates[deploymentList.Items[i].Name] = &deploymentList.Items[i].Spec.Template.Spec
+	}
+	for i := range statefulSetList.Items {
+		templates[statefulSetList.Items[i].Name] = &statefulSetList.Items[i].Spec.Template.Spec
+	}
+
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("deployment %s do not exist", id)
+	}
+
+	pods, err := m.getPods(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	podMetricsList, err := m.kc.PodMetrics(ctx, ns, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			return nil, fmt.Errorf("metrics api is not available, metrics-server must be running in the cluster: %w", err)
+		}
+		return nil, err
+	}
+
+	return deploymentMetrics(id, templates, pods, podMetricsList.Items), nil
+}
+
+// deploymentMetrics sums the usage of the pods by service, pods has the service name of every pod by pod name
+func deploymentMetrics(id types.DeploymentID, templates map[string]*corev1.PodSpec, pods map[string]string, podMetrics []metricsv1beta1.PodMetrics) *types.DeploymentMetrics {
+	services := make(map[string]*types.ServiceMetrics, len(templates))
+	cpu := make(map[string]*resource.Quantity, len(templates))
+	memory := make(map[string]*resource.Quantity, len(templates))
+	for name, spec := range templates {
+		requests, limits := podResources(spec)
+		services[name] = &types.ServiceMetrics{ServiceName: name, Requests: requests, Limits: limits, Pods: make([]*types.PodMetrics, 0)}
+		cpu[name], memory[name] = resource.NewQuantity(0, resource.DecimalSI), resource.NewQuantity(0, resource.BinarySI)
+	}
+
+	for i := range podMetrics {
+		pm := &podMetrics[i]
+		name := pods[pm.Name]
+		service, ok := services[name]
+		if !ok {
+			continue
+		}
+
+		podCPU, podMemory := resource.NewQuantity(0, resource.DecimalSI), resource.NewQuantity(0, resource.BinarySI)
+		for _, container := range pm.Containers {
+			podCPU.Add(*container.Usage.Cpu())
+			podMemory.Add(*container.Usage.Memory())
+		}
+		cpu[name].Add(*podCPU)
+		memory[name].Add(*podMemory)
+
+		service.Pods = append(service.Pods, &types.PodMetrics{PodName: pm.Name, Usage: resourceUsage(podCPU, podMemory), Time: pm.Timestamp.Time})
+	}
+
+	metrics := &types.DeploymentMetrics{ID: id, Services: make([]*types.ServiceMetrics, 0, len(services))}
+	for name, service := range services {
+		service.Usage = resourceUsage(cpu[name], memory[name])
+		sort.Slice(service.Pods, func(i, j int) bool {
+			return service.Pods[i].PodName < service.Pods[j].PodName
+		})
+		metrics.Services = append(metrics.Services, service)
+	}
+
+	sort.Slice(metrics.Services, func(i, j int) bool {
+		return metrics.Services[i].ServiceName < metrics.Services[j].ServiceName
+	})
+
+	return metrics
+}
+
+// podResources returns the resources requested by the containers of the pod and their limits
+func podResources(spec *corev1.PodSpec) (types.ResourceUsage, types.ResourceUsage) {
+	requestsCPU, requestsMemory := resource.NewQuantity(0, resource.DecimalSI), resource.NewQuantity(0, resource.BinarySI)
+	limitsCPU, limitsMemory := resource.NewQuantity(0, resource.DecimalSI), resource.NewQuantity(0, resource.BinarySI)
+	for _, container := range spec.Containers {
+		requestsCPU.Add(*container.Resources.Requests.Cpu())
+		requestsMemory.Add(*container.Resources.Requests.Memory())
+		limitsCPU.Add(*container.Resources.Limits.Cpu())
+		limitsMemory.Add(*container.Resources.Limits.Memory())
+	}
+
+	return resourceUsage(requestsCPU, requestsMemory), resourceUsage(limitsCPU, limitsMemory)
+}
+
+func resourceUsage(cpu, memory *resource.Quantity) types.ResourceUsage {
+	return types.ResourceUsage{CPU: cpu.AsApproximateFloat64(), Memory: memory.Value() / 1000000}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestDeploymentMetrics(t *testing.T) {
+	web := &corev1.PodSpec{Containers: []corev1.Container{{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100M")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("200M")},
+		},
+	}}}
+	templates := map[string]*corev1.PodSpec{"web": web, "db": {}}
+	pods := map[string]string{"web-1": "web", "web-2": "web"}
+
+	usage := func(pod, cpu, memory string) metricsv1beta1.PodMetrics {
+		return metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: pod},
+			Containers: []metricsv1beta1.ContainerMetrics{{
+				Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			}},
+		}
+	}
+	podMetrics := []metricsv1beta1.PodMetrics{usage("web-2", "250m", "150M"), usage("web-1", "50m", "50M"), usage("other", "1", "1G")}
+
+	metrics := deploymentMetrics("1", templates, pods, podMetrics)
+	require.Len(t, metrics.Services, 2)
+
+	db := metrics.Services[0]
+	require.Equal(t, "db", db.ServiceName)
+	require.Empty(t, db.Pods)
+
+	service := metrics.Services[1]
+	require.Equal(t, types.ResourceUsage{CPU: 0.1, Memory: 100}, service.Requests)
+	require.Equal(t, types.ResourceUsage{CPU: 0.5, Memory: 200}, service.Limits)
+	require.Equal(t, types.ResourceUsage{CPU: 0.3, Memory: 200}, service.Usage)
+	require.Len(t, service.Pods, 2)
+	require.Equal(t, "web-1", service.Pods[0].PodName)
+	require.Equal(t, types.ResourceUsage{CPU: 0.05, Memory: 50}, service.Pods[0].Usage)
+}
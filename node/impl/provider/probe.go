@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/manifest"
+)
+
+// probesToManifestProbes validates the probes of a service and converts them to the manifest probes
+func probesToManifestProbes(probes types.Probes) (*manifest.ServiceProbes, error) {
+	if probes.Liveness == nil && probes.Readiness == nil && probes.Startup == nil {
+		return nil, nil
+	}
+
+	liveness, err := probeToManifestProbe(types.ProbeLiveness, probes.Liveness)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness, err := probeToManifestProbe(types.ProbeReadiness, probes.Readiness)
+	if err != nil {
+		return nil, err
+	}
+
+	startup, err := probeToManifestProbe(types.ProbeStartup, probes.Startup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest.ServiceProbes{Liveness: liveness, Readiness: readiness, Startup: startup}, nil
+}
+
+func probeToManifestProbe(kind types.ProbeKind, probe *types.Probe) (*manifest.Probe, error) {
+	if probe == nil {
+		return nil, nil
+	}
+
+	thresholds := []int{probe.InitialDelaySeconds, probe.PeriodSeconds, probe.TimeoutSeconds, probe.SuccessThreshold, probe.FailureThreshold}
+	for _, threshold := range thresholds {
+		if threshold < 0 {
+			return nil, fmt.Errorf("%s probe thresholds can not be negative", kind)
+		}
+	}
+
+	// kubernetes only accepts a success threshold of 1 for the liveness and startup probes
+	if kind != types.ProbeReadiness && probe.SuccessThreshold > 1 {
+		return nil, fmt.Errorf("%s probe success threshold must be 1", kind)
+	}
+
+	out := &manifest.Probe{
+		InitialDelaySeconds: int32(probe.InitialDelaySeconds),
+		PeriodSeconds:       int32(probe.PeriodSeconds),
+		TimeoutSeconds:      int32(probe.TimeoutSeconds),
+		SuccessThreshold:    int32(probe.SuccessThreshold),
+		FailureThreshold:    int32(probe.FailureThreshold),
+	}
+
+	actions := 0
+	if probe.HTTPGet != nil {
+		actions++
+
+		path := probe.HTTPGet.Path
+		if len(path) == 0 {
+			path = "/"
+		}
+
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("%s probe http path must start with /", kind)
+		}
+
+		if probe.HTTPGet.Port <= 0 || probe.HTTPGet.Port > 65535 {
+			return nil, fmt.Errorf("%s probe http port %d is invalid", kind, probe.HTTPGet.Port)
+		}
+
+		out.HTTPGet = &manifest.HTTPGetProbe{Path: path, Port: uint32(probe.HTTPGet.Port)}
+	}
+
+	if probe.TCPSocket != nil {
+		actions++
+
+		if probe.TCPSocket.Port <= 0 || probe.TCPSocket.Port > 65535 {
+			return nil, fmt.Errorf("%s probe tcp port %d is invalid", kind, probe.TCPSocket.Port)
+		}
+
+		out.TCPSocket = &manifest.TCPSocketProbe{Port: uint32(probe.TCPSocket.Port)}
+	}
+
+	if probe.Exec != nil {
+		actions++
+
+		if len(probe.Exec.Command) == 0 {
+			return nil, fmt.Errorf("%s probe exec command can not empty", kind)
+		}
+
+		out.Exec = &manifest.ExecProbe{Command: probe.Exec.Command}
+	}
+
+	if actions != 1 {
+		return nil, fmt.Errorf("%s probe must set exactly one of http get, tcp socket and exec", kind)
+	}
+
+	return out, nil
+}
@@ -32,10 +32,26 @@ func (p *Provider) GetStatistics(ctx context.Context) (*types.ResourcesStatistic
 	return p.Manager.GetStatistics(ctx)
 }
 
+func (p *Provider) CheckCapacity(ctx context.Context, deployment *types.Deployment) error {
+	return p.Manager.CheckCapacity(ctx, deployment)
+}
+
 func (p *Provider) GetDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) {
 	return p.Manager.GetDeployment(ctx, id)
 }
 
+func (p *Provider) GetAppliedDeployment(ctx context.Context, id types.DeploymentID) (*types.Deployment, error) {
+	return p.Manager.GetAppliedDeployment(ctx, id)
+}
+
+func (p *Provider) ListDeployments(ctx context.Context) ([]*types.Deployment, error) {
+	return p.Manager.ListDeployments(ctx)
+}
+
+func (p *Provider) WatchDeployments(ctx context.Context) (<-chan *types.Deployment, error) {
+	return p.Manager.WatchDeployments(ctx)
+}
+
 func (p *Provider) CreateDeployment(ctx context.Context, deployment *types.Deployment) error {
 	return p.Manager.CreateDeployment(ctx, deployment)
 }
@@ -51,6 +67,26 @@ func (p *Provider) CloseDeployment(ctx context.Context, deployment *types.Deploy
 func (p *Provider) GetLogs(ctx context.Context, id types.DeploymentID) ([]*types.ServiceLog, error) {
 	return p.Manager.GetLogs(ctx, id)
 }
+func (p *Provider) GetLogStream(ctx context.Context, id types.DeploymentID, opt *types.LogOption) (<-chan *types.LogLine, error) {
+	return p.Manager.GetLogStream(ctx, id, opt)
+}
+
 func (p *Provider) GetEvents(ctx context.Context, id types.DeploymentID) ([]*types.ServiceEvent, error) {
 	return p.Manager.GetEvents(ctx, id)
 }
+
+func (p *Provider) GetDeploymentMetrics(ctx context.Context, id types.DeploymentID) (*types.DeploymentMetrics, error) {
+	return p.Manager.GetDeploymentMetrics(ctx, id)
+}
+
+func (p *Provider) ScaleDeployment(ctx context.Context, id types.DeploymentID, serviceName string, replicas int) error {
+	return p.Manager.ScaleDeployment(ctx, id, serviceName, replicas)
+}
+
+func (p *Provider) ExecDeployment(ctx context.Context, id types.DeploymentID, opt *types.ExecOption) (<-chan *types.ExecOutput, error) {
+	return p.Manager.ExecDeployment(ctx, id, opt)
+}
+
+func (p *Provider) ExecInput(ctx context.Context, input *types.ExecInput) error {
+	return p.Manager.ExecInput(ctx, input)
+}
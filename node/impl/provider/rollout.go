@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/gnasnik/titan-container/node/impl/provider/kube/builder"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// reasons of the Progressing condition of a kubernetes deployment
+const (
+	reasonNewReplicaSetAvailable   = "NewReplicaSetAvailable"
+	reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+// stateSeverity orders the rollout states of the workloads, the state of a deployment is the worst state of its workloads
+var stateSeverity = map[types.DeploymentState]int{
+	types.DeploymentStateRunning:   0,
+	types.DeploymentStateDeploying: 1,
+	types.DeploymentStateDegraded:  2,
+	types.DeploymentStateFailed:    3,
+}
+
+// getRolloutState returns the state of the deployment in the namespace from the rollout status of its workloads
+func (m *manager) getRolloutState(ctx context.Context, ns string) (types.DeploymentState, string, error) {
+	cache, err := m.getCache()
+	if err != nil {
+		return 0, "", err
+	}
+
+	deploymentList, err := cache.ListDeployments(ns)
+	if err != nil {
+		return 0, "", err
+	}
+
+	statefulSetList, err := cache.ListStatefulSets(ns)
+	if err != nil {
+		return 0, "", err
+	}
+
+	podList, err := cache.ListPods(ns, labels.SelectorFromSet(labels.Set{builder.TitanManagedLabelName: "true"}))
+	if err != nil {
+		return 0, "", err
+	}
+
+	state, reason := rolloutState(deploymentList.Items, statefulSetList.Items, podList.Items)
+	return state, reason, nil
+}
+
+// rolloutState aggregates the rollout states of the workloads, a deployment with no running pod is pending.
+func rolloutState(deployments []appsv1.Deployment, statefulSets []appsv1.StatefulSet, pods []corev1.Pod) (types.DeploymentState, string) {
+	if len(deployments) == 0 && len(statefulSets) == 0 {
+		return types.DeploymentStatePending, "no workloads"
+	}
+
+	state, reason := types.DeploymentStateRunning, ""
+	worst := func(s types.DeploymentState, r string) {
+		if stateSeverity[s] > stateSeverity[state] {
+			state, reason = s, r
+		}
+	}
+
+	for i := range deployments {
+		worst(deploymentRolloutState(&deployments[i]))
+	}
+	for i := range statefulSets {
+		worst(statefulSetRolloutState(&statefulSets[i]))
+	}
+
+	if state == types.DeploymentStateDeploying || state == types.DeploymentStateDegraded {
+		if pending, r := podsPending(pods); pending {
+			return types.DeploymentStatePending, r
+		}
+	}
+
+	return state, reason
+}
+
+// deploymentRolloutState follows the rollout status of kubectl, a deployment whose rollout completed
+// before and which lost available replicas is degraded
+func deploymentRolloutState(d *appsv1.Deployment) (types.DeploymentState, string) {
+	var progressing *appsv1.DeploymentCondition
+	for i, condition := range d.Status.Conditions {
+		switch {
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue:
+			return types.DeploymentStateFailed, fmt.Sprintf("service %s: %s", d.Name, condition.Message)
+		case condition.Type == appsv1.DeploymentProgressing:
+			progressing = &d.Status.Conditions[i]
+		}
+	}
+
+	if progressing != nil && progressing.Reason == reasonProgressDeadlineExceeded {
+		return types.DeploymentStateFailed, fmt.Sprintf("service %s: %s", d.Name, progressing.Message)
+	}
+
+	desired := replicasOf(d.Spec.Replicas)
+	switch {
+	case d.Status.ObservedGeneration < d.Generation:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: waiting for the spec update to be observed", d.Name)
+	case d.Status.UpdatedReplicas < desired:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: %d of %d updated replicas", d.Name, d.Status.UpdatedReplicas, desired)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: %d old replicas pending termination", d.Name, d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		reason := fmt.Sprintf("service %s: %d of %d updated replicas available", d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+		if progressing != nil && progressing.Reason == reasonNewReplicaSetAvailable {
+			return types.DeploymentStateDegraded, reason
+		}
+		return types.DeploymentStateDeploying, reason
+	}
+
+	return types.DeploymentStateRunning, ""
+}
+
+// statefulSetRolloutState returns degraded for a statefulset whose pods are updated but not all ready,
+// the manager keeps a deployment that never ran deploying
+func statefulSetRolloutState(s *appsv1.StatefulSet) (types.DeploymentState, string) {
+	desired := replicasOf(s.Spec.Replicas)
+	switch {
+	case s.Status.ObservedGeneration < s.Generation:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: waiting for the spec update to be observed", s.Name)
+	case s.Status.UpdatedReplicas < desired:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: %d of %d updated replicas", s.Name, s.Status.UpdatedReplicas, desired)
+	case s.Status.UpdateRevision != "" && s.Status.CurrentRevision != s.Status.UpdateRevision:
+		return types.DeploymentStateDeploying, fmt.Sprintf("service %s: waiting for the update to revision %s", s.Name, s.Status.UpdateRevision)
+	case s.Status.ReadyReplicas < desired:
+		return types.DeploymentStateDegraded, fmt.Sprintf("service %s: %d of %d replicas ready", s.Name, s.Status.ReadyReplicas, desired)
+	}
+
+	return types.DeploymentStateRunning, ""
+}
+
+// podsPending reports whether none of the pods is running, with the reason of the first pod waiting to be scheduled or started
+func podsPending(pods []corev1.Pod) (bool, string) {
+	reason := "no pod created"
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodRunning {
+			return false, ""
+		}
+
+		if i > 0 {
+			continue
+		}
+
+		reason = fmt.Sprintf("pod %s: %s", pod.Name, pod.Status.Phase)
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				reason = fmt.Sprintf("pod %s: %s: %s", pod.Name, condition.Reason, condition.Message)
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				reason = fmt.Sprintf("pod %s: %s", pod.Name, status.State.Waiting.Reason)
+			}
+		}
+	}
+
+	return true, reason
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/gnasnik/titan-container/api/types"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDeploymentRolloutState(t *testing.T) {
+	replicas := int32(2)
+	newDeployment := func(status appsv1.DeploymentStatus) *appsv1.Deployment {
+		d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}, Status: status}
+		d.Name = "web"
+		return d
+	}
+	progressing := func(reason string) []appsv1.DeploymentCondition {
+		return []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: reason}}
+	}
+
+	cases := []struct {
+		status appsv1.DeploymentStatus
+		expect types.DeploymentState
+	}{
+		{appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}, types.DeploymentStateRunning},
+		{appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1}, types.DeploymentStateDeploying},
+		{appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2}, types.DeploymentStateDeploying},
+		{appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1, Conditions: progressing("ReplicaSetUpdated")}, types.DeploymentStateDeploying},
+		{appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1, Conditions: progressing(reasonNewReplicaSetAvailable)}, types.DeploymentStateDegraded},
+		{appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, Conditions: progressing(reasonProgressDeadlineExceeded)}, types.DeploymentStateFailed},
+	}
+
+	for _, c := range cases {
+		state, _ := deploymentRolloutState(newDeployment(c.status))
+		require.Equal(t, types.DeploymentStateString(c.expect), types.DeploymentStateString(state))
+	}
+}
+
+func TestRolloutStatePending(t *testing.T) {
+	replicas := int32(1)
+	deployments := []appsv1.Deployment{{Spec: appsv1.DeploymentSpec{Replicas: &replicas}, Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1}}}
+
+	pod := corev1.Pod{Status: corev1.PodStatus{
+		Phase:      corev1.PodPending,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: "0/1 nodes are available"}},
+	}}
+	pod.Name = "web-0"
+
+	state, reason := rolloutState(deployments, nil, []corev1.Pod{pod})
+	require.Equal(t, types.DeploymentStatePending, state)
+	require.Equal(t, "pod web-0: Unschedulable: 0/1 nodes are available", reason)
+
+	pod.Status.Phase = corev1.PodRunning
+	state, _ = rolloutState(deployments, nil, []corev1.Pod{pod})
+	require.Equal(t, types.DeploymentStateDeploying, state)
+
+	state, _ = rolloutState(nil, nil, nil)
+	require.Equal(t, types.DeploymentStatePending, state)
+}
@@ -14,6 +14,9 @@ type APIAlg jwt.HMACSHA
 
 type APIEndpoint multiaddr.Multiaddr
 
+// DataEncryptionKey is the key used by the manager to encrypt the sensitive data it stores
+type DataEncryptionKey []byte
+
 type ProviderID string
 
 // InternalIP local network address
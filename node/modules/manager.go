@@ -1,15 +1,55 @@
 package modules
 
 import (
+	"crypto/rand"
+	"errors"
+	"io"
+
 	"github.com/gnasnik/titan-container/db"
 	"github.com/gnasnik/titan-container/node/config"
+	"github.com/gnasnik/titan-container/node/modules/dtypes"
 	"github.com/gnasnik/titan-container/node/repo"
+	"github.com/gnasnik/titan-container/node/types"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/xerrors"
 )
 
 var log = logging.Logger("modules")
 
+const (
+	DataEncryptionKeyName = "data-encryption-key"
+	KTDataEncryptionKey   = "aes-256-gcm-key"
+)
+
+// DataEncryptionKey returns the key the manager encrypts the sensitive deployment data with,
+// the key is generated and stored in the keystore on first use.
+func DataEncryptionKey(keystore types.KeyStore) (dtypes.DataEncryptionKey, error) {
+	key, err := keystore.Get(DataEncryptionKeyName)
+
+	if errors.Is(err, types.ErrKeyInfoNotFound) {
+		log.Warn("Generating new data encryption key")
+
+		sk, err := io.ReadAll(io.LimitReader(rand.Reader, 32))
+		if err != nil {
+			return nil, err
+		}
+
+		key = types.KeyInfo{
+			Type:       KTDataEncryptionKey,
+			PrivateKey: sk,
+		}
+
+		if err := keystore.Put(DataEncryptionKeyName, key); err != nil {
+			return nil, xerrors.Errorf("writing data encryption key: %w", err)
+		}
+	} else if err != nil {
+		return nil, xerrors.Errorf("could not get data encryption key: %w", err)
+	}
+
+	return dtypes.DataEncryptionKey(key.PrivateKey), nil
+}
+
 // NewManagerDB creates a new database connection for managing managers.
 // It takes a DSN (Data Source Name) string as input and returns a pointer to sqlx.DB and an error.
 func NewManagerDB(dsn string) func() (*sqlx.DB, error) {
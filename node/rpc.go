@@ -77,6 +77,7 @@ func ManagerHandler(a api.Manager, permissioned bool, opts ...jsonrpc.ServerOpti
 	}
 
 	serveRpc("/rpc/v0", fnapi)
+	m.Handle("/debug/metrics", metrics.Exporter())
 	m.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
 
 	return m, nil
@@ -98,6 +99,7 @@ func ProviderHandler(authv func(ctx context.Context, token string) ([]auth.Permi
 
 	mux.Handle("/rpc/v0", rpcServer)
 	mux.Handle("/rpc/streams/v0/push/{uuid}", readerHandler)
+	mux.Handle("/debug/metrics", metrics.Exporter())
 	mux.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
 
 	if !permissioned {